@@ -0,0 +1,20 @@
+package cachetest
+
+import "time"
+
+type Option func(*Server)
+
+// WithDefaultTTL sets the TTL applied to entries stored without
+// an explicit x-cache-ttl header. By default entries never expire.
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.defaultTTL = ttl
+	}
+}
+
+// WithClock replaces the time source used for TTL expiration.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
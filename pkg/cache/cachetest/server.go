@@ -0,0 +1,327 @@
+// Package cachetest provides an in-process fake of the Cache service.
+//
+// The fake implements the /v1/cache contract used by cache.Client, so
+// tests can exercise real round trips instead of asserting headers in
+// one-off handlers. Stored entries can be inspected and failures
+// (latency, error responses, timeouts) can be injected.
+package cachetest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+const (
+	cachePath = "/v1/cache"
+
+	headerKey       = "x-cache-key"
+	headerNamespace = "x-cache-namespace"
+	headerScope     = "x-cache-scope"
+	headerTTL       = "x-cache-ttl"
+)
+
+// Entry is a value stored in the fake cache.
+type Entry struct {
+	Key       string
+	Namespace string
+	Scope     string
+	Value     []byte
+
+	// ExpiresAt is the time after which the entry is no longer
+	// returned. A zero value means the entry never expires.
+	ExpiresAt time.Time
+}
+
+type entryKey struct {
+	key       string
+	namespace string
+	scope     string
+}
+
+type fault struct {
+	statusCode int
+	hang       bool
+}
+
+// Server is a fake Cache service listening on a local address.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	entries    map[entryKey]Entry
+	faults     []fault
+	latency    time.Duration
+	defaultTTL time.Duration
+	now        func() time.Time
+	requests   int
+	closed     chan struct{}
+	closeOnce  sync.Once
+}
+
+// NewServer starts and returns a new fake Cache service.
+// The caller should call Close when finished, to shut it down.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		entries: make(map[entryKey]Entry),
+		now:     time.Now,
+		closed:  make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(cachePath, s.handle)
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Close releases hanging requests and shuts down the server.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() { close(s.closed) })
+	s.Server.Close()
+}
+
+// Put stores a value directly, bypassing the HTTP interface.
+// A ttl of zero stores the value with the server default TTL.
+func (s *Server) Put(key, namespace, scope string, value []byte, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store(entryKey{key, namespace, scope}, value, ttl)
+}
+
+// Entry returns the stored entry for the given key, namespace and scope.
+// Expired entries are not returned.
+func (s *Server) Entry(key, namespace, scope string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lookup(entryKey{key, namespace, scope})
+}
+
+// Entries returns all entries which are not expired, ordered by
+// namespace, scope and key.
+func (s *Server) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []Entry
+	for k := range s.entries {
+		if e, ok := s.lookup(k); ok {
+			entries = append(entries, e)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Namespace != entries[j].Namespace {
+			return entries[i].Namespace < entries[j].Namespace
+		}
+		if entries[i].Scope != entries[j].Scope {
+			return entries[i].Scope < entries[j].Scope
+		}
+		return entries[i].Key < entries[j].Key
+	})
+
+	return entries
+}
+
+// Requests returns the number of requests received on the cache endpoint.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// Reset removes all entries, pending failures and latency.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = make(map[entryKey]Entry)
+	s.faults = nil
+	s.latency = 0
+	s.requests = 0
+}
+
+// SetLatency delays every following response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = d
+}
+
+// FailNext makes the next n requests fail with the given HTTP status code.
+// The response body is a JSON encoded err.Error of the matching Kind.
+func (s *Server) FailNext(n int, statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		s.faults = append(s.faults, fault{statusCode: statusCode})
+	}
+}
+
+// TimeoutNext makes the next n requests hang without a response
+// until the client gives up or the server is closed.
+func (s *Server) TimeoutNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		s.faults = append(s.faults, fault{hang: true})
+	}
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	latency := s.latency
+	var f *fault
+	if len(s.faults) > 0 {
+		f = &s.faults[0]
+		s.faults = s.faults[1:]
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		case <-s.closed:
+			return
+		}
+	}
+
+	if f != nil {
+		if f.hang {
+			select {
+			case <-r.Context().Done():
+			case <-s.closed:
+			}
+			return
+		}
+		errors.JSON(w, errors.New(errors.GetKind(f.statusCode), "injected failure"), f.statusCode)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.get(w, r)
+	case http.MethodPost:
+		s.set(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		errors.JSON(w, errors.New(errors.BadRequest, "method not allowed"), http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	k, ok := requestKey(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	e, found := s.lookup(k)
+	s.mu.Unlock()
+
+	if !found {
+		errors.JSON(w, errors.New(errors.NotFound, "key not found in cache"))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(e.Value)
+}
+
+func (s *Server) set(w http.ResponseWriter, r *http.Request) {
+	k, ok := requestKey(w, r)
+	if !ok {
+		return
+	}
+
+	var ttl time.Duration
+	if v := r.Header.Get(headerTTL); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 0 {
+			errors.JSON(w, errors.New(errors.BadRequest, "invalid cache ttl"))
+			return
+		}
+		ttl = time.Duration(seconds) * time.Second
+	}
+
+	value, err := io.ReadAll(r.Body)
+	if err != nil {
+		errors.JSON(w, errors.New(errors.BadRequest, "failed to read request body", err))
+		return
+	}
+
+	s.mu.Lock()
+	s.store(k, value, ttl)
+	s.mu.Unlock()
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// store must be called with s.mu held.
+func (s *Server) store(k entryKey, value []byte, ttl time.Duration) {
+	if ttl == 0 {
+		ttl = s.defaultTTL
+	}
+
+	e := Entry{
+		Key:       k.key,
+		Namespace: k.namespace,
+		Scope:     k.scope,
+		Value:     append([]byte(nil), value...),
+	}
+	if ttl > 0 {
+		e.ExpiresAt = s.now().Add(ttl)
+	}
+
+	s.entries[k] = e
+}
+
+// lookup must be called with s.mu held.
+func (s *Server) lookup(k entryKey) (Entry, bool) {
+	e, ok := s.entries[k]
+	if !ok {
+		return Entry{}, false
+	}
+
+	if !e.ExpiresAt.IsZero() && !s.now().Before(e.ExpiresAt) {
+		delete(s.entries, k)
+		return Entry{}, false
+	}
+
+	e.Value = append([]byte(nil), e.Value...)
+	return e, true
+}
+
+func requestKey(w http.ResponseWriter, r *http.Request) (entryKey, bool) {
+	k := entryKey{
+		key:       r.Header.Get(headerKey),
+		namespace: r.Header.Get(headerNamespace),
+		scope:     r.Header.Get(headerScope),
+	}
+
+	if k.key == "" {
+		errors.JSON(w, errors.New(errors.BadRequest, "missing cache key header"))
+		return k, false
+	}
+
+	return k, true
+}
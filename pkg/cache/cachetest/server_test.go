@@ -0,0 +1,156 @@
+package cachetest_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/cache"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/cache/cachetest"
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestServer_SetGet(t *testing.T) {
+	srv := cachetest.NewServer()
+	defer srv.Close()
+
+	client := cache.New(srv.URL)
+
+	err := client.Set(context.Background(), "mykey", "mynamespace", "myscope", []byte("data"))
+	require.NoError(t, err)
+
+	res, err := client.Get(context.Background(), "mykey", "mynamespace", "myscope")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), res)
+
+	// the same key in another scope is a different entry
+	res, err = client.Get(context.Background(), "mykey", "mynamespace", "otherscope")
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(errors.NotFound, err))
+
+	entry, ok := srv.Entry("mykey", "mynamespace", "myscope")
+	require.True(t, ok)
+	assert.Equal(t, []byte("data"), entry.Value)
+	assert.True(t, entry.ExpiresAt.IsZero())
+	assert.Len(t, srv.Entries(), 1)
+	assert.Equal(t, 3, srv.Requests())
+}
+
+func TestServer_MissingKey(t *testing.T) {
+	srv := cachetest.NewServer()
+	defer srv.Close()
+
+	err := cache.New(srv.URL).Set(context.Background(), "", "mynamespace", "myscope", []byte("data"))
+	assert.True(t, errors.Is(errors.BadRequest, err))
+	assert.Empty(t, srv.Entries())
+}
+
+func TestServer_TTL(t *testing.T) {
+	clk := &clock{now: time.Now()}
+	srv := cachetest.NewServer(cachetest.WithClock(clk.Now), cachetest.WithDefaultTTL(time.Minute))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/v1/cache", bytes.NewReader([]byte("short")))
+	require.NoError(t, err)
+	req.Header.Set("x-cache-key", "short")
+	req.Header.Set("x-cache-ttl", "10")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	srv.Put("long", "", "", []byte("long"), 0)
+
+	entry, ok := srv.Entry("short", "", "")
+	require.True(t, ok)
+	assert.Equal(t, clk.Now().Add(10*time.Second), entry.ExpiresAt)
+
+	clk.Add(30 * time.Second)
+	_, ok = srv.Entry("short", "", "")
+	assert.False(t, ok)
+	_, ok = srv.Entry("long", "", "")
+	assert.True(t, ok)
+
+	clk.Add(time.Minute)
+	_, err = cache.New(srv.URL).Get(context.Background(), "long", "", "")
+	assert.True(t, errors.Is(errors.NotFound, err))
+	assert.Empty(t, srv.Entries())
+}
+
+func TestServer_FailNext(t *testing.T) {
+	srv := cachetest.NewServer()
+	defer srv.Close()
+
+	srv.Put("mykey", "", "", []byte("data"), 0)
+	srv.FailNext(3, http.StatusServiceUnavailable)
+
+	client := cache.New(srv.URL, cache.WithRetries(2, time.Millisecond, time.Millisecond))
+	_, err := client.Get(context.Background(), "mykey", "", "")
+	assert.True(t, errors.Is(errors.ServiceUnavailable, err))
+	assert.Equal(t, 3, srv.Requests())
+
+	// a temporary failure is retried
+	srv.FailNext(1, http.StatusServiceUnavailable)
+	res, err := client.Get(context.Background(), "mykey", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), res)
+	assert.Equal(t, 5, srv.Requests())
+
+	// permanent failures are not
+	srv.FailNext(1, http.StatusForbidden)
+	_, err = client.Get(context.Background(), "mykey", "", "")
+	assert.True(t, errors.Is(errors.Forbidden, err))
+	assert.Equal(t, 6, srv.Requests())
+}
+
+func TestServer_TimeoutNext(t *testing.T) {
+	srv := cachetest.NewServer()
+	defer srv.Close()
+
+	srv.TimeoutNext(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := cache.New(srv.URL).Get(ctx, "mykey", "", "")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestServer_SetLatency(t *testing.T) {
+	srv := cachetest.NewServer()
+	defer srv.Close()
+
+	srv.SetLatency(50 * time.Millisecond)
+
+	start := time.Now()
+	err := cache.New(srv.URL).Set(context.Background(), "mykey", "", "", []byte("data"))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	srv.Reset()
+	assert.Empty(t, srv.Entries())
+	assert.Equal(t, 0, srv.Requests())
+}
@@ -9,13 +9,18 @@ import (
 	"net/http"
 	"net/url"
 
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/encryption"
 	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/internal/httpclient"
 )
 
 // Client for the Cache service.
 type Client struct {
 	addr       string
 	httpClient *http.Client
+	client     *httpclient.Client
+	clientOpts []httpclient.Option
+	envelope   *encryption.Envelope
 }
 
 func New(addr string, opts ...Option) *Client {
@@ -28,10 +33,23 @@ func New(addr string, opts ...Option) *Client {
 		opt(c)
 	}
 
+	c.client = httpclient.New(c.httpClient, c.clientOpts...)
+
 	return c
 }
 
+// Set stores the value in the cache. If the client is configured
+// WithEncryption, the value is encrypted for the subject found in ctx
+// (see encryption.WithSubject) before it leaves the process.
 func (c *Client) Set(ctx context.Context, key, namespace, scope string, value []byte) error {
+	if c.envelope != nil {
+		var err error
+		value, err = c.envelope.Encrypt(ctx, encryption.GetSubject(ctx), value)
+		if err != nil {
+			return errors.New("failed to encrypt cache value", err)
+		}
+	}
+
 	requestURI := c.addr + "/v1/cache"
 	cacheURL, err := url.ParseRequestURI(requestURI)
 	if err != nil {
@@ -49,20 +67,22 @@ func (c *Client) Set(ctx context.Context, key, namespace, scope string, value []
 		"x-cache-scope":     []string{scope},
 	}
 
-	resp, err := c.httpClient.Do(req)
+	// storing a value under the same key twice has the same effect
+	resp, err := c.client.Do(ctx, req, httpclient.Idempotent())
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close() // nolint:errcheck
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		msg := fmt.Sprintf("unexpected response: %s", resp.Status)
-		return errors.New(errors.GetKind(resp.StatusCode), msg)
+		return errors.FromResponse(resp, fmt.Sprintf("unexpected response: %s", resp.Status))
 	}
 
 	return nil
 }
 
+// Get retrieves a value from the cache and decrypts it,
+// if the client is configured WithEncryption.
 func (c *Client) Get(ctx context.Context, key, namespace, scope string) ([]byte, error) {
 	requestURI := c.addr + "/v1/cache"
 	cacheURL, err := url.ParseRequestURI(requestURI)
@@ -71,28 +91,34 @@ func (c *Client) Get(ctx context.Context, key, namespace, scope string) ([]byte,
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", cacheURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
 	req.Header = http.Header{
 		"x-cache-key":       []string{key},
 		"x-cache-namespace": []string{namespace},
 		"x-cache-scope":     []string{scope},
 	}
-	if err != nil {
-		return nil, err
-	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.client.Do(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close() // nolint:errcheck
 
 	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, errors.New(errors.NotFound)
-		}
-		msg := fmt.Sprintf("unexpected response: %s", resp.Status)
-		return nil, errors.New(errors.GetKind(resp.StatusCode), msg)
+		return nil, errors.FromResponse(resp, fmt.Sprintf("unexpected response: %s", resp.Status))
+	}
+
+	value, err := io.ReadAll(resp.Body)
+	if err != nil || c.envelope == nil {
+		return value, err
+	}
+
+	value, err = c.envelope.Decrypt(ctx, value)
+	if err != nil {
+		return nil, errors.New("failed to decrypt cache value", err)
 	}
 
-	return io.ReadAll(resp.Body)
+	return value, nil
 }
@@ -1,6 +1,7 @@
 package cache_test
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 	"net/http/httptest"
@@ -8,8 +9,11 @@ import (
 
 	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/eclipse-xfsc/microservice-core-go/pkg/cache"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/cache/cachetest"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/encryption"
 )
 
 func TestClient_InvalidCacheAddress(t *testing.T) {
@@ -184,3 +188,35 @@ func TestClient_Set(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_WithEncryption(t *testing.T) {
+	keyring, err := encryption.NewKeyring("master", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	env := encryption.New(keyring, encryption.WithKeyStore(encryption.NewMemoryKeyStore()))
+
+	cachesrv := cachetest.NewServer()
+	defer cachesrv.Close()
+
+	client := cache.New(cachesrv.URL, cache.WithEncryption(env))
+	ctx := encryption.WithSubject(context.Background(), "user-1")
+
+	err = client.Set(ctx, "mykey", "mynamespace", "myscope", []byte("jane@example.com"))
+	require.NoError(t, err)
+
+	// the cache service only receives the ciphertext
+	entry, ok := cachesrv.Entry("mykey", "mynamespace", "myscope")
+	require.True(t, ok)
+	assert.NotContains(t, string(entry.Value), "jane@example.com")
+
+	res, err := client.Get(ctx, "mykey", "mynamespace", "myscope")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("jane@example.com"), res)
+
+	// shredding the subject key makes the cached value unreadable
+	require.NoError(t, env.Shred(ctx, "user-1"))
+	res, err = client.Get(ctx, "mykey", "mynamespace", "myscope")
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(errors.Gone, err))
+	assert.False(t, errors.Is(errors.NotFound, err))
+	assert.Contains(t, err.Error(), "failed to decrypt cache value")
+}
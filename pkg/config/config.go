@@ -1,68 +1,31 @@
 package config
 
 import (
-	"errors"
-	"fmt"
-	"strings"
-
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
 	"github.com/eclipse-xfsc/microservice-core-go/pkg/server"
-
-	"github.com/spf13/viper"
 )
 
-var viperInstance = viper.New()
-
 // BaseConfig can be used to import the base config parameters in the applications config struct.
 // Please use with tag `mapstructure:",squash"`.
 type BaseConfig struct {
-	LogLevel   string            `mapstructure:"logLevel" envconfig:"LOG_LEVEL" default:"info"`
-	IsDev      bool              `mapstructure:"isDev" envconfig:"IS_DEV" default:"false"`
-	ListenAddr string            `mapstructure:"listenAddr" envconfig:"LISTEN_ADDR" default:"127.0.0.1"`
-	ListenPort int               `mapstructure:"listenPort" envconfig:"LISTEN_PORT" default:"8080"`
-	ServerMode server.ServerMode `mapstructure:"serverMode" default:"production"`
-}
-
-// LoadConfig sets given defaults and read in given config.
-func LoadConfig(prefix string, config any, defaults map[string]any) error {
-	setDefaults(defaults)
-
-	if err := readConfig(prefix); err != nil {
-		return err
-	}
-
-	if err := viperInstance.Unmarshal(config); err != nil {
-		return err
-	}
-
-	return nil
+	LogLevel   string            `mapstructure:"logLevel" envconfig:"LOG_LEVEL" default:"info" desc:"log level, e.g. debug, info or error"`
+	IsDev      bool              `mapstructure:"isDev" envconfig:"IS_DEV" default:"false" desc:"enables development logging"`
+	ListenAddr string            `mapstructure:"listenAddr" envconfig:"LISTEN_ADDR" default:"127.0.0.1" desc:"address the server listens on"`
+	ListenPort int               `mapstructure:"listenPort" envconfig:"LISTEN_PORT" default:"8080" desc:"port the server listens on"`
+	ServerMode server.ServerMode `mapstructure:"serverMode" default:"production" desc:"server mode: debug, testing or production"`
 }
 
-func readConfig(prefix string) error {
-	viperInstance.SetConfigName("config")
-	viperInstance.SetConfigType("yaml")
-	viperInstance.AddConfigPath(".")
-
-	viperInstance.SetEnvPrefix(strings.ToTitle(prefix))
-	viperInstance.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-
-	viperInstance.AutomaticEnv()
-
-	if err := viperInstance.ReadInConfig(); err != nil {
-		if !errors.Is(err, viper.ConfigFileNotFoundError{}) {
-			return fmt.Errorf("error read in configFile: %w", err)
-		}
+// SetStackCapture enables capturing the call stack of errors if IsDev is
+// set, see errors.SetStackCapture. As this changes the whole process,
+// Load doesn't do it; services call it once after loading their config.
+func (c *BaseConfig) SetStackCapture() {
+	if c.IsDev {
+		errors.SetStackCapture(true)
 	}
-
-	return nil
 }
 
-func setDefaults(defaults map[string]any) {
-	viperInstance.SetDefault("logLevel", "info")
-	viperInstance.SetDefault("listenAddr", "127.0.0.1")
-	viperInstance.SetDefault("listenPort", 8080)
-	viperInstance.SetDefault("serverMode", server.ModeProduction)
-
-	for key, value := range defaults {
-		viperInstance.SetDefault(key, value)
-	}
+// LoadConfig sets given defaults and read in given config.
+// It is a shorthand for NewLoader(prefix).Load(config, defaults).
+func LoadConfig(prefix string, config any, defaults map[string]any) error {
+	return NewLoader(prefix).Load(config, defaults)
 }
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the values of secrets in the effective config.
+const Redacted = "[redacted]"
+
+// secretNames are parts of key names which mark a value as secret if it
+// has no secret tag.
+var secretNames = []string{"password", "passwd", "secret", "token", "dsn", "apikey", "privatekey", "credential"}
+
+// Value is a value of the effective config.
+type Value struct {
+	// Value is the loaded value, or Redacted for non-empty secrets.
+	Value any `json:"value"`
+
+	Source Source `json:"source"`
+
+	Secret bool `json:"secret,omitempty"`
+}
+
+// Effective returns the values of the last successful Load with their
+// sources, keyed by config key. Secrets are redacted; a value is secret if
+// its field is tagged with `secret:"true"` or, without tag, if the last
+// part of its key contains one of password, passwd, secret, token, dsn,
+// apikey, privatekey or credential, ignoring case, - and _. Use
+// `secret:"false"` to show such a value. Values of maps with string keys,
+// e.g. headers, are redacted by the same rule applied to their keys.
+//
+// The result can be exposed by the GinServer, see
+// server.GinServer.SetConfigProvider.
+func (l *Loader) Effective() map[string]Value {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	effective := make(map[string]Value, len(l.effective))
+	for key, value := range l.effective {
+		effective[key] = value
+	}
+
+	return effective
+}
+
+// effectiveValues returns the redacted values of the loaded config.
+func effectiveValues(config any, fs []field, sources map[string]Source) map[string]Value {
+	v := reflect.ValueOf(config)
+
+	effective := make(map[string]Value, len(fs))
+	for _, f := range fs {
+		value := Value{Source: sources[f.key], Secret: isSecret(f)}
+		if fv, ok := f.value(v); ok {
+			switch {
+			case value.Secret && !fv.IsZero():
+				value.Value = Redacted
+			case value.Secret:
+				value.Value = ""
+			default:
+				value.Value = redactMap(fv)
+			}
+		}
+		effective[f.key] = value
+	}
+
+	return effective
+}
+
+func isSecret(f field) bool {
+	if tag, ok := f.Tag.Lookup("secret"); ok {
+		return tag == "true"
+	}
+
+	return isSecretName(f.key[strings.LastIndexByte(f.key, '.')+1:])
+}
+
+// isSecretName reports whether the name contains one of the secretNames,
+// ignoring case and the separators - and _, e.g. X-Api-Key.
+func isSecretName(name string) bool {
+	name = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	for _, secret := range secretNames {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactMap returns the value with the entries of maps with string keys
+// redacted whose key is a secret name, see isSecretName. Nested maps are
+// redacted as well; other values are returned unchanged.
+func redactMap(value reflect.Value) any {
+	v := value
+	for (v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer) && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String || v.IsNil() {
+		return value.Interface()
+	}
+
+	redacted := make(map[string]any, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, value := iter.Key().String(), iter.Value()
+		switch {
+		case isSecretName(key) && !value.IsZero():
+			redacted[key] = Redacted
+		default:
+			redacted[key] = redactMap(value)
+		}
+	}
+
+	return redacted
+}
@@ -0,0 +1,51 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/config"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/db/postgres"
+)
+
+type effectiveConfig struct {
+	config.BaseConfig `mapstructure:",squash"`
+	Postgres          postgres.Config `mapstructure:"postgres"`
+	OAuth             struct {
+		ClientSecret string `mapstructure:"clientSecret"`
+		TokenURL     string `mapstructure:"tokenUrl" secret:"false"`
+	} `mapstructure:"oAuth"`
+	CacheDSN string            `mapstructure:"cacheDsn"`
+	Signing  string            `mapstructure:"signing" secret:"true"`
+	Headers  map[string]string `mapstructure:"headers"`
+}
+
+func TestLoader_Effective(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "oAuth:\n  clientSecret: s3cret\n  tokenUrl: https://auth.example.com/token\n"+
+		"headers:\n  X-Api-Key: k3y\n  Authorization-Token: t0ken\n  Accept: application/json\n")
+	t.Setenv("EFFECTIVE_LOG_LEVEL", "debug")
+
+	loader := config.NewLoader("effective", config.WithSearchPaths(dir))
+	var cfg effectiveConfig
+	require.NoError(t, loader.Load(&cfg, nil))
+
+	effective := loader.Effective()
+	assert.Equal(t, config.Value{Value: "debug", Source: config.Source{Kind: config.SourceEnv, Name: "EFFECTIVE_LOG_LEVEL"}}, effective["logLevel"])
+	assert.Equal(t, config.Value{Value: 5432, Source: config.Source{Kind: config.SourceDefault}}, effective["postgres.port"])
+	assert.Equal(t, config.Value{Value: config.Redacted, Source: config.Source{Kind: config.SourceDefault}, Secret: true}, effective["postgres.password"])
+	assert.Equal(t, config.Redacted, effective["oAuth.clientSecret"].Value)
+	assert.Equal(t, "https://auth.example.com/token", effective["oAuth.tokenUrl"].Value)
+	assert.Equal(t, config.Value{Value: "", Source: config.Source{Kind: config.SourceUnset}, Secret: true}, effective["cacheDsn"])
+	assert.True(t, effective["signing"].Secret)
+
+	// map values are redacted by their key
+	assert.Equal(t, map[string]any{
+		"x-api-key":           config.Redacted,
+		"authorization-token": config.Redacted,
+		"accept":              "application/json",
+	}, effective["headers"].Value)
+	assert.False(t, effective["headers"].Secret)
+}
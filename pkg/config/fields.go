@@ -0,0 +1,184 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// field is a leaf value of a config struct.
+type field struct {
+	// key is the viper key, e.g. "postgres.host".
+	key string
+
+	// path is the path of Go field names, e.g. "Postgres.Host".
+	path string
+
+	// env is the environment variable without prefix, e.g. "POSTGRES_HOST".
+	// It is taken from the envconfig tags of the field and its parents.
+	env string
+
+	// flag is the command-line flag, e.g. "postgres.host" or "listen-port".
+	// It is taken from the envconfig tag of the field, or the key.
+	flag string
+
+	// index is the index sequence for reflect.Value.FieldByIndex.
+	index []int
+
+	reflect.StructField
+}
+
+// fields returns the leaf fields of the config struct type t. Nested
+// structs are keyed by their mapstructure name, embedded structs tagged
+// with ",squash" are flattened into their parent, like viper.Unmarshal
+// decodes them.
+func fields(t reflect.Type) []field {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return appendFields(nil, t, field{})
+}
+
+// appendFields appends the fields of the struct type t. The key, path, env
+// and flag of parent are the prefixes of the fields, including separator.
+func appendFields(fs []field, t reflect.Type, parent field) []field {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, squash := mapstructureName(sf)
+		if name == "-" {
+			continue
+		}
+
+		f := field{
+			key:         parent.key + name,
+			path:        parent.path + sf.Name,
+			env:         parent.env + envconfigName(sf, name),
+			flag:        parent.flag + kebabCase(name),
+			index:       append(append([]int(nil), parent.index...), i),
+			StructField: sf,
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+			nested := parent
+			nested.path, nested.index = f.path+".", f.index
+			if !squash {
+				nested.key, nested.env, nested.flag = f.key+".", f.env+"_", f.flag+"."
+			}
+			fs = appendFields(fs, ft, nested)
+			continue
+		}
+
+		if tag := sf.Tag.Get("envconfig"); tag != "" {
+			f.flag = parent.flag + strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+		}
+
+		fs = append(fs, f)
+	}
+
+	return fs
+}
+
+// mapstructureName returns the key of the field and whether it is squashed
+// into its parent. Fields without tag are keyed by their name.
+func mapstructureName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("mapstructure")
+	name, opts, _ := strings.Cut(tag, ",")
+
+	squash := false
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == "squash" {
+			squash = true
+		}
+	}
+
+	if name == "" {
+		name = sf.Name
+	}
+
+	return name, squash
+}
+
+// envconfigName returns the envconfig tag of the field, or the upper case
+// key if there is none.
+func envconfigName(sf reflect.StructField, key string) string {
+	if name := sf.Tag.Get("envconfig"); name != "" {
+		return name
+	}
+
+	return strings.ToUpper(key)
+}
+
+// kebabCase converts a camel case key to kebab case, e.g. "listenPort"
+// to "listen-port".
+func kebabCase(key string) string {
+	b := new(strings.Builder)
+	for i, r := range key {
+		if unicode.IsUpper(r) {
+			if i > 0 && !unicode.IsUpper(rune(key[i-1])) {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// value returns the value of the field in the config struct v, and false
+// if it is not reachable due to a nil pointer.
+func (f field) value(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+
+	fv, err := v.FieldByIndexErr(f.index)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+
+	return fv, true
+}
+
+// envNames returns the environment variables of the field: the name
+// derived from the envconfig tags and the name viper.AutomaticEnv looks
+// up for the key, if it differs.
+func (l *Loader) envNames(f field) []string {
+	names := []string{l.withPrefix(f.env)}
+	if name := l.envName(f.key); name != names[0] {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// envName returns the environment variable of the key, as looked up by
+// viper.AutomaticEnv.
+func (l *Loader) envName(key string) string {
+	return l.withPrefix(strings.ToUpper(strings.ReplaceAll(key, ".", "_")))
+}
+
+func (l *Loader) withPrefix(name string) string {
+	if l.prefix == "" {
+		return name
+	}
+
+	return strings.ToUpper(l.prefix) + "_" + name
+}
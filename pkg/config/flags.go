@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// configFlag is the flag of the config file, see WithConfigFile.
+const configFlag = "config"
+
+// BindFlags adds a flag for each key of the config struct to the flag set
+// and binds them into the loader. Set flags take precedence over all other
+// sources. Flags of nested structs are prefixed with the key of the struct,
+// e.g. --postgres.host; flag names are taken from the envconfig tag (e.g.
+// --listen-port for LISTEN_PORT) or the key in kebab case. The usage text
+// is taken from the `desc` tag and lists the environment variable of the
+// key, so the usage printed for --help documents both.
+//
+// The flag --config sets the config file like WithConfigFile, unless the
+// flag set already has a flag with this name. Keys of types without flag
+// support, e.g. slices of structs or nested maps, get no flag; their keys
+// are returned. BindFlags must be called before the flags are parsed:
+//
+//	loader := config.NewLoader("EXAMPLE")
+//	loader.BindFlags(pflag.CommandLine, &ExampleConfig)
+//	pflag.Parse()
+//	err := loader.Load(&ExampleConfig, getDefaults())
+func (l *Loader) BindFlags(flags *pflag.FlagSet, config any) (skipped []string) {
+	l.mu.Lock()
+	l.flags = flags
+	l.mu.Unlock()
+
+	if flags.Lookup(configFlag) == nil {
+		flags.String(configFlag, "", "config file, replaces the config files of the search paths")
+	}
+
+	for _, f := range fields(reflect.TypeOf(config)) {
+		if flags.Lookup(f.flag) == nil {
+			usage := f.Tag.Get("desc")
+			if usage != "" {
+				usage += " "
+			}
+			usage += fmt.Sprintf("(env %s)", l.envNames(f)[0])
+
+			if !addFlag(flags, f.flag, f.Type, usage) {
+				skipped = append(skipped, f.key)
+				continue
+			}
+			if value, ok := f.Tag.Lookup("default"); ok {
+				// shown in the usage, the default itself is set in viper
+				flags.Lookup(f.flag).DefValue = value
+			}
+		}
+	}
+
+	return skipped
+}
+
+// addFlag adds a flag of the given type. It returns false for types
+// without flag support, e.g. nested maps.
+func addFlag(flags *pflag.FlagSet, name string, t reflect.Type, usage string) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Duration(0)) {
+		flags.Duration(name, 0, usage)
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		flags.String(name, "", usage)
+	case reflect.Bool:
+		flags.Bool(name, false, usage)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		flags.Int64(name, 0, usage)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		flags.Uint64(name, 0, usage)
+	case reflect.Float32, reflect.Float64:
+		flags.Float64(name, 0, usage)
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String {
+			return false
+		}
+		flags.StringSlice(name, nil, usage)
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String || t.Elem().Kind() != reflect.String {
+			return false
+		}
+		flags.StringToString(name, nil, usage)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// bindFlags binds the flags of the fields into viper and returns the
+// explicit config file of the --config flag, if set.
+func (l *Loader) bindFlags(fs []field) (string, error) {
+	if l.flags == nil {
+		return "", nil
+	}
+
+	for _, f := range fs {
+		if flag := l.flags.Lookup(f.flag); flag != nil {
+			if err := l.viper.BindPFlag(f.key, flag); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if flag := l.flags.Lookup(configFlag); flag != nil && flag.Changed {
+		return flag.Value.String(), nil
+	}
+
+	return "", nil
+}
+
+// changedFlag returns the flag of the field if it was set.
+func (l *Loader) changedFlag(f field) (*pflag.Flag, bool) {
+	if l.flags == nil {
+		return nil, false
+	}
+
+	flag := l.flags.Lookup(f.flag)
+	if flag == nil || !flag.Changed {
+		return nil, false
+	}
+
+	return flag, true
+}
@@ -0,0 +1,75 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/config"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/db/postgres"
+)
+
+type flagConfig struct {
+	config.BaseConfig `mapstructure:",squash"`
+	Postgres          postgres.Config `mapstructure:"postgres"`
+	OAuth             struct {
+		ServerUrl string   `mapstructure:"serverUrl" desc:"OAuth server"`
+		Scopes    []string `mapstructure:"scopes"`
+	} `mapstructure:"oAuth"`
+}
+
+func TestLoader_BindFlags(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "service.yaml", "logLevel: warn\nlistenPort: 7070\n")
+	t.Setenv("FLAGS_LISTEN_PORT", "8081")
+
+	var cfg flagConfig
+	loader := config.NewLoader("flags")
+	flags := pflag.NewFlagSet("service", pflag.ContinueOnError)
+	assert.Empty(t, loader.BindFlags(flags, &cfg))
+	require.NoError(t, flags.Parse([]string{
+		"--config", filepath.Join(dir, "service.yaml"),
+		"--listen-port", "9090",
+		"--postgres.host", "db.example.com",
+		"--o-auth.scopes", "openid,profile",
+	}))
+
+	require.NoError(t, loader.Load(&cfg, nil))
+	assert.Equal(t, "warn", cfg.LogLevel)
+	assert.Equal(t, 9090, cfg.ListenPort)
+	assert.Equal(t, "db.example.com", cfg.Postgres.Host)
+	assert.Equal(t, 5432, cfg.Postgres.Port)
+	assert.Equal(t, []string{"openid", "profile"}, cfg.OAuth.Scopes)
+
+	sources := loader.Sources()
+	assert.Equal(t, config.Source{Kind: config.SourceFlag, Name: "--listen-port"}, sources["listenPort"])
+	assert.Equal(t, config.SourceDefault, sources["postgres.port"].Kind)
+}
+
+func TestLoader_BindFlagsSkipped(t *testing.T) {
+	var cfg struct {
+		Name    string                       `mapstructure:"name"`
+		Ports   []int                        `mapstructure:"ports"`
+		Routing map[string]map[string]string `mapstructure:"routing"`
+	}
+
+	flags := pflag.NewFlagSet("service", pflag.ContinueOnError)
+	skipped := config.NewLoader("example").BindFlags(flags, &cfg)
+	assert.Equal(t, []string{"ports", "routing"}, skipped)
+	assert.NotNil(t, flags.Lookup("name"))
+	assert.Nil(t, flags.Lookup("ports"))
+}
+
+func TestLoader_BindFlagsUsage(t *testing.T) {
+	flags := pflag.NewFlagSet("service", pflag.ContinueOnError)
+	config.NewLoader("example").BindFlags(flags, &flagConfig{})
+
+	usage := flags.FlagUsages()
+	assert.Contains(t, usage, "--config string")
+	assert.Regexp(t, `--listen-port int\s+port the server listens on \(env EXAMPLE_LISTEN_PORT\) \(default 8080\)`, usage)
+	assert.Regexp(t, `--postgres.host string\s+database host \(env EXAMPLE_POSTGRES_HOST\) \(default "127.0.0.1"\)`, usage)
+	assert.Regexp(t, `--o-auth.server-url string\s+OAuth server \(env EXAMPLE_OAUTH_SERVERURL\)`, usage)
+}
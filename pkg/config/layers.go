@@ -0,0 +1,201 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// formats are the supported config file formats in the order they are
+// searched for.
+var formats = []string{"yaml", "yml", "json", "toml"}
+
+// layer is a config file merged into the config.
+type layer struct {
+	file  string
+	viper *viper.Viper
+}
+
+// readFiles merges the config files from lowest to highest precedence:
+//
+//  1. <name>.<ext> of the search paths, the first search path last
+//  2. <name>.<env>.<ext> of the search paths, the first search path last
+//
+// With an explicit config file (see WithConfigFile), only the file and
+// its overlay of the same directory are merged, e.g. app.yaml and
+// app.production.yaml.
+//
+// The environment is given by WithEnvironment, the environment variable
+// <PREFIX>_ENV or the key serverMode, in this order.
+func (l *Loader) readFiles(configFile string) error {
+	l.layers = nil
+	defer func() {
+		l.candidates = l.candidateFiles(configFile)
+	}()
+
+	var files []string
+	if configFile != "" {
+		files = []string{configFile}
+	} else {
+		files = l.findFiles(l.fileName)
+	}
+
+	for _, file := range files {
+		if err := l.mergeFile(file); err != nil {
+			return err
+		}
+	}
+
+	env := l.environment()
+	if env == "" {
+		return nil
+	}
+
+	var overlays []string
+	if configFile != "" {
+		ext := filepath.Ext(configFile)
+		overlay := strings.TrimSuffix(configFile, ext) + "." + env + ext
+		if isFile(overlay) {
+			overlays = []string{overlay}
+		}
+	} else {
+		overlays = l.findFiles(l.fileName + "." + env)
+	}
+
+	for _, file := range overlays {
+		if err := l.mergeFile(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findFiles returns the config files with the given name in the search
+// paths, from lowest to highest precedence. Per directory, the first
+// file of the supported formats is used.
+func (l *Loader) findFiles(name string) []string {
+	exts := formats
+	if l.format != "" {
+		exts = []string{l.format}
+	}
+
+	var files []string
+	seen := map[string]bool{}
+	for i := len(l.paths) - 1; i >= 0; i-- {
+		for _, ext := range exts {
+			file := filepath.Join(l.paths[i], name+"."+ext)
+			if abs, err := filepath.Abs(file); err == nil {
+				file = abs
+			}
+			if seen[file] || !isFile(file) {
+				continue
+			}
+			seen[file] = true
+			files = append(files, file)
+			break
+		}
+	}
+
+	return files
+}
+
+// candidateFiles returns all config files readFiles would merge if they
+// existed, so a Watcher notices files created after the last Load, e.g.
+// a new overlay of the environment.
+func (l *Loader) candidateFiles(configFile string) []string {
+	env := l.environment()
+
+	if configFile != "" {
+		files := []string{configFile}
+		if env != "" {
+			ext := filepath.Ext(configFile)
+			files = append(files, strings.TrimSuffix(configFile, ext)+"."+env+ext)
+		}
+		return absPaths(files)
+	}
+
+	exts := formats
+	if l.format != "" {
+		exts = []string{l.format}
+	}
+
+	names := []string{l.fileName}
+	if env != "" {
+		names = append(names, l.fileName+"."+env)
+	}
+
+	var files []string
+	for _, path := range l.paths {
+		for _, name := range names {
+			for _, ext := range exts {
+				files = append(files, filepath.Join(path, name+"."+ext))
+			}
+		}
+	}
+
+	return absPaths(files)
+}
+
+// mergeFile reads the config file and merges it into the config.
+func (l *Loader) mergeFile(file string) error {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if l.format != "" {
+		v.SetConfigType(l.format)
+	}
+
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("error read in configFile %s: %w", file, err)
+	}
+
+	if err := l.viper.MergeConfigMap(v.AllSettings()); err != nil {
+		return fmt.Errorf("error merge configFile %s: %w", file, err)
+	}
+
+	l.layers = append(l.layers, layer{file: file, viper: v})
+
+	return nil
+}
+
+// fileOf returns the config file of highest precedence which contains
+// the key.
+func (l *Loader) fileOf(key string) (string, bool) {
+	for i := len(l.layers) - 1; i >= 0; i-- {
+		if l.layers[i].viper.InConfig(key) {
+			return l.layers[i].file, true
+		}
+	}
+
+	return "", false
+}
+
+func (l *Loader) environment() string {
+	if l.env != "" {
+		return l.env
+	}
+
+	if env := os.Getenv(l.withPrefix("ENV")); env != "" {
+		return env
+	}
+
+	return l.viper.GetString("serverMode")
+}
+
+func absPaths(files []string) []string {
+	for i, file := range files {
+		if abs, err := filepath.Abs(file); err == nil {
+			files[i] = abs
+		}
+	}
+
+	return files
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,92 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/config"
+)
+
+type layeredConfig struct {
+	config.BaseConfig `mapstructure:",squash"`
+	Name              string `mapstructure:"name"`
+	Region            string `mapstructure:"region"`
+	Replicas          int    `mapstructure:"replicas"`
+	Feature           string `mapstructure:"feature"`
+}
+
+func TestLoader_Layers(t *testing.T) {
+	local, etc := t.TempDir(), t.TempDir()
+	writeFile(t, etc, "config.yaml", "name: etc\nregion: eu\nreplicas: 1\nfeature: off\n")
+	writeFile(t, etc, "config.production.toml", "replicas = 3\n")
+	writeFile(t, local, "config.json", `{"name": "local"}`)
+	writeFile(t, local, "config.production.yml", "feature: on\n")
+	t.Setenv("LAYERS_REGION", "us")
+
+	var cfg layeredConfig
+	loader := config.NewLoader("layers", config.WithSearchPaths(local, etc))
+	require.NoError(t, loader.Load(&cfg, nil))
+
+	assert.Equal(t, "local", cfg.Name)
+	assert.Equal(t, "us", cfg.Region)
+	assert.Equal(t, 3, cfg.Replicas)
+	assert.Equal(t, "on", cfg.Feature)
+
+	sources := loader.Sources()
+	assert.Equal(t, config.Source{Kind: config.SourceFile, Name: filepath.Join(local, "config.json")}, sources["name"])
+	assert.Equal(t, config.Source{Kind: config.SourceFile, Name: filepath.Join(etc, "config.production.toml")}, sources["replicas"])
+	assert.Equal(t, config.Source{Kind: config.SourceEnv, Name: "LAYERS_REGION"}, sources["region"])
+}
+
+func TestLoader_WorkingDirectory(t *testing.T) {
+	wd, etc := t.TempDir(), t.TempDir()
+	writeFile(t, wd, "config.yaml", "name: local\n")
+	writeFile(t, etc, "config.yaml", "name: etc\nregion: eu\n")
+	t.Chdir(wd)
+
+	// the search paths are searched in addition to the working directory
+	var cfg layeredConfig
+	require.NoError(t, config.NewLoader("layers", config.WithSearchPaths(etc)).Load(&cfg, nil))
+	assert.Equal(t, "local", cfg.Name)
+	assert.Equal(t, "eu", cfg.Region)
+}
+
+func TestLoader_Environment(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "name: base\nserverMode: debug\n")
+	writeFile(t, dir, "config.debug.yaml", "name: debug\n")
+	writeFile(t, dir, "config.staging.yaml", "name: staging\n")
+
+	var cfg layeredConfig
+	require.NoError(t, config.NewLoader("envs", config.WithSearchPaths(dir)).Load(&cfg, nil))
+	assert.Equal(t, "debug", cfg.Name)
+
+	t.Setenv("ENVS_ENV", "staging")
+	require.NoError(t, config.NewLoader("envs", config.WithSearchPaths(dir)).Load(&cfg, nil))
+	assert.Equal(t, "staging", cfg.Name)
+
+	require.NoError(t, config.NewLoader("envs", config.WithSearchPaths(dir), config.WithEnvironment("none")).Load(&cfg, nil))
+	assert.Equal(t, "base", cfg.Name)
+}
+
+func TestLoader_ConfigFile(t *testing.T) {
+	dir, other := t.TempDir(), t.TempDir()
+	writeFile(t, dir, "config.yaml", "name: search\n")
+	writeFile(t, other, "app.toml", "name = \"explicit\"\nregion = \"eu\"\n")
+	writeFile(t, other, "app.production.toml", "region = \"us\"\n")
+
+	var cfg layeredConfig
+	loader := config.NewLoader("file",
+		config.WithSearchPaths(dir),
+		config.WithConfigFile(filepath.Join(other, "app.toml")),
+	)
+	require.NoError(t, loader.Load(&cfg, nil))
+	assert.Equal(t, "explicit", cfg.Name)
+	assert.Equal(t, "us", cfg.Region)
+
+	err := config.NewLoader("file", config.WithConfigFile(filepath.Join(other, "missing.yaml"))).Load(&cfg, nil)
+	assert.ErrorContains(t, err, "error read in configFile")
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+)
+
+// Loader loads configuration from config files and environment variables
+// into config structs. Every Loader has its own viper instance, so loaders
+// with different prefixes or defaults don't affect each other.
+type Loader struct {
+	prefix     string
+	fileName   string
+	paths      []string
+	format     string
+	configFile string
+	env        string
+	tenantDir  string
+
+	logger       logr.Logger
+	pollInterval time.Duration
+
+	// mu guards the state below. Load holds it while reading the config,
+	// so concurrent Loads and readers don't see a half read state.
+	mu    sync.RWMutex
+	viper *viper.Viper
+
+	// flags are bound by BindFlags.
+	flags *pflag.FlagSet
+
+	// layers are the config files merged by the last Load.
+	layers []layer
+
+	// candidates are the config files the last Load would have merged if
+	// they existed.
+	candidates []string
+
+	// results of the last successful Load
+	sources     map[string]Source
+	effective   map[string]Value
+	settings    map[string]any
+	tenants     map[string]map[string]any
+	configFiles []string
+	secretFiles []string
+}
+
+// NewLoader creates a Loader for environment variables with the given
+// prefix. By default, it reads the optional files config.yaml and
+// config.<env>.yaml from the working directory, see Load.
+func NewLoader(prefix string, opts ...Option) *Loader {
+	l := &Loader{
+		viper:    viper.New(),
+		prefix:   prefix,
+		fileName: "config",
+		paths:    []string{"."},
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Load sets the defaults, reads in the config files and environment
+// variables, unmarshals the result into config and validates it. All
+// invalid values are reported in one *ValidationError, see FieldError.
+//
+// Values are taken from the following sources, each overriding the
+// previous ones:
+//
+//  1. defaults
+//  2. config.<ext> of the search paths, the first search path last
+//  3. config.<env>.<ext> of the search paths, the first search path last
+//  4. environment variables
+//  5. command-line flags, see BindFlags
+//
+// The extension is one of yaml, yml, json or toml unless the format is
+// set with WithFormat. The environment <env> is given by WithEnvironment,
+// the environment variable <PREFIX>_ENV or the key serverMode, e.g.
+// config.production.yaml. With WithConfigFile, the given file and its
+// overlay of the same directory replace the files of the search paths.
+//
+// The defaults are taken from the `default` tags of the config struct and
+// its nested structs; entries of the given defaults map take precedence.
+// Each key is read from the environment variable derived from the
+// `envconfig` tags (e.g. EXAMPLE_LISTEN_PORT for listenPort of the
+// BaseConfig) and from the one derived from the key (EXAMPLE_LISTENPORT),
+// in this order.
+//
+// Secrets can be kept out of config files and environment: a key without
+// environment variable is read from the file named by the variable with
+// suffix _FILE, e.g. EXAMPLE_POSTGRES_PASSWORD_FILE=/run/secrets/pg.
+// String values of the form file://<path> are replaced with the content
+// of the file, values of the form env://<name> with the environment
+// variable. Sources returns where each value came from.
+//
+// The section tenants.<id> and the files of the tenant directory hold
+// overrides of single tenants, see LoadTenant.
+func (l *Loader) Load(config any, defaults map[string]any) error {
+	// the whole Load is locked, as it replaces the viper instance and
+	// layers, which concurrent Loads of a Watcher would mix up
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// a fresh viper instance drops the values of the previous Load,
+	// e.g. resolved secrets
+	l.viper = viper.New()
+
+	fs := fields(reflect.TypeOf(config))
+	defaulted := l.setDefaults(fs, defaults)
+
+	if err := l.readConfig(fs); err != nil {
+		return err
+	}
+
+	sources, secretFiles, err := l.resolve(fs, defaulted)
+	if err != nil {
+		return err
+	}
+
+	if err := l.viper.Unmarshal(config); err != nil {
+		return err
+	}
+
+	if err := l.validate(config); err != nil {
+		return err
+	}
+
+	tenants, tenantFiles, err := l.readTenants()
+	if err != nil {
+		return err
+	}
+	tenantSecretFiles, err := dereferenceTenants(tenants)
+	if err != nil {
+		return err
+	}
+	secretFiles = append(secretFiles, tenantSecretFiles...)
+
+	settings := l.viper.AllSettings()
+	delete(settings, tenantsKey)
+
+	configFiles := make([]string, 0, len(l.layers)+len(tenantFiles))
+	for _, layer := range l.layers {
+		configFiles = append(configFiles, layer.file)
+	}
+	configFiles = append(configFiles, tenantFiles...)
+	effective := effectiveValues(config, fs, sources)
+
+	l.sources, l.effective = sources, effective
+	l.settings, l.tenants = settings, tenants
+	l.configFiles, l.secretFiles = configFiles, secretFiles
+
+	return nil
+}
+
+func (l *Loader) readConfig(fs []field) error {
+	l.viper.SetEnvPrefix(strings.ToTitle(l.prefix))
+	l.viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+	l.viper.AutomaticEnv()
+
+	if err := l.bindEnv(fs); err != nil {
+		return err
+	}
+
+	configFile, err := l.bindFlags(fs)
+	if err != nil {
+		return err
+	}
+	if configFile == "" {
+		configFile = l.configFile
+	}
+
+	return l.readFiles(configFile)
+}
+
+// setDefaults sets the defaults and returns their lower case keys.
+func (l *Loader) setDefaults(fs []field, defaults map[string]any) map[string]bool {
+	defaulted := map[string]bool{}
+
+	for _, f := range fs {
+		if value, ok := f.Tag.Lookup("default"); ok {
+			l.viper.SetDefault(f.key, defaultValue(f, value))
+			defaulted[strings.ToLower(f.key)] = true
+		}
+	}
+
+	for key, value := range defaults {
+		l.viper.SetDefault(key, value)
+		markDefaulted(defaulted, strings.ToLower(key), value)
+	}
+
+	return defaulted
+}
+
+// markDefaulted adds the key and the keys of nested maps to defaulted.
+func markDefaulted(defaulted map[string]bool, key string, value any) {
+	defaulted[key] = true
+
+	if m, ok := value.(map[string]any); ok {
+		for k, v := range m {
+			markDefaulted(defaulted, key+"."+strings.ToLower(k), v)
+		}
+	}
+}
+
+// defaultValue converts the default tag of maps from the envconfig format
+// "key1:value1,key2:value2". Other values are converted by viper.Unmarshal,
+// which splits slices at commas.
+func defaultValue(f field, value string) any {
+	if f.Type.Kind() != reflect.Map {
+		return value
+	}
+
+	m := map[string]any{}
+	for _, pair := range strings.Split(value, ",") {
+		if k, v, ok := strings.Cut(pair, ":"); ok {
+			m[k] = v
+		}
+	}
+
+	return m
+}
+
+// bindEnv binds the keys of the config struct to their environment
+// variables, so nested keys are read from the environment without
+// default or config file entry.
+func (l *Loader) bindEnv(fs []field) error {
+	for _, f := range fs {
+		if err := l.viper.BindEnv(append([]string{f.key}, l.envNames(f)...)...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// files returns the config and secret files read by the last successful
+// Load.
+func (l *Loader) files() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return append(append([]string(nil), l.configFiles...), l.secretFiles...)
+}
+
+// watchFiles returns the files of files and the candidate config files of
+// the last Load, which change the config when they are created.
+func (l *Loader) watchFiles() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	seen := map[string]bool{}
+	var files []string
+	for _, list := range [][]string{l.configFiles, l.secretFiles, l.candidates} {
+		for _, file := range list {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+
+	return files
+}
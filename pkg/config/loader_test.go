@@ -0,0 +1,151 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/config"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/db/postgres"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/db/redis"
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/server"
+)
+
+type testConfig struct {
+	config.BaseConfig `mapstructure:",squash"`
+	TestValue         string `mapstructure:"testValue"`
+	OAuth             struct {
+		ServerUrl string `mapstructure:"serverUrl"`
+	} `mapstructure:"oAuth"`
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+}
+
+func TestLoader_Load(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "service.json", `{"testValue": "from file", "oAuth": {"serverUrl": "https://auth.example.com"}}`)
+	t.Setenv("EXAMPLE_LISTENPORT", "9090")
+
+	var cfg testConfig
+	loader := config.NewLoader("example",
+		config.WithFileName("service"),
+		config.WithFormat("json"),
+		config.WithSearchPaths(dir),
+	)
+	require.NoError(t, loader.Load(&cfg, map[string]any{"testValue": "default"}))
+
+	assert.Equal(t, "from file", cfg.TestValue)
+	assert.Equal(t, "https://auth.example.com", cfg.OAuth.ServerUrl)
+	assert.Equal(t, 9090, cfg.ListenPort)
+	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, server.ModeProduction, cfg.ServerMode)
+}
+
+func TestLoader_Isolated(t *testing.T) {
+	var first, second testConfig
+	require.NoError(t, config.NewLoader("first").Load(&first, map[string]any{"testValue": "first"}))
+	require.NoError(t, config.NewLoader("second").Load(&second, nil))
+
+	assert.Equal(t, "first", first.TestValue)
+	assert.Empty(t, second.TestValue)
+}
+
+func TestLoader_InvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "testValue: [unclosed")
+
+	var cfg testConfig
+	err := config.NewLoader("example", config.WithSearchPaths(dir)).Load(&cfg, nil)
+	assert.ErrorContains(t, err, "error read in configFile")
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("COMPAT_TESTVALUE", "from env")
+
+	var cfg testConfig
+	require.NoError(t, config.LoadConfig("compat", &cfg, map[string]any{"testValue": "default"}))
+	assert.Equal(t, "from env", cfg.TestValue)
+	assert.Equal(t, 8080, cfg.ListenPort)
+}
+
+func TestLoader_ConcurrentLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "testValue: from file")
+
+	loader := config.NewLoader("example", config.WithSearchPaths(dir))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var cfg testConfig
+			assert.NoError(t, loader.Load(&cfg, nil))
+			assert.Equal(t, "from file", cfg.TestValue)
+			assert.Equal(t, config.SourceFile, loader.Sources()["testValue"].Kind)
+			assert.NotEmpty(t, loader.Effective())
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLoader_StackCapture(t *testing.T) {
+	defer errors.SetStackCapture(errors.StackCapture())
+	errors.SetStackCapture(false)
+
+	var cfg testConfig
+	require.NoError(t, config.NewLoader("example").Load(&cfg, nil))
+	cfg.SetStackCapture()
+	assert.False(t, errors.StackCapture())
+
+	// loading a development config has no side effect
+	t.Setenv("EXAMPLE_IS_DEV", "true")
+	require.NoError(t, config.NewLoader("example").Load(&cfg, nil))
+	assert.True(t, cfg.IsDev)
+	assert.False(t, errors.StackCapture())
+
+	cfg.SetStackCapture()
+	assert.True(t, errors.StackCapture())
+}
+
+type taggedConfig struct {
+	config.BaseConfig `mapstructure:",squash"`
+	Postgres          postgres.Config `mapstructure:"postgres" envconfig:"DB"`
+	Redis             redis.Config    `mapstructure:"redis"`
+	Timeout           time.Duration   `mapstructure:"timeout" default:"5s"`
+	Scopes            []string        `mapstructure:"scopes" default:"openid,profile"`
+}
+
+func TestLoader_Tags(t *testing.T) {
+	t.Setenv("TAGS_LOG_LEVEL", "debug")
+	t.Setenv("TAGS_DB_HOST", "db.example.com")
+	t.Setenv("TAGS_REDIS_PORT", "6380")
+
+	var cfg taggedConfig
+	require.NoError(t, config.NewLoader("tags").Load(&cfg, map[string]any{"postgres.port": 6543}))
+
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, "127.0.0.1", cfg.ListenAddr)
+	assert.Equal(t, 8080, cfg.ListenPort)
+	assert.Equal(t, server.ModeProduction, cfg.ServerMode)
+	assert.Equal(t, postgres.Config{
+		Host:     "db.example.com",
+		Port:     6543,
+		Database: "postgres",
+		User:     "postgres",
+		Password: "postgres",
+		Params:   map[string]string{"sslmode": "require"},
+	}, cfg.Postgres)
+	assert.Equal(t, redis.Config{Hosts: "127.0.0.1", Port: 6380}, cfg.Redis)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"openid", "profile"}, cfg.Scopes)
+}
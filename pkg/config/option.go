@@ -0,0 +1,78 @@
+package config
+
+import (
+	"time"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+)
+
+type Option func(*Loader)
+
+// WithFileName sets the name of the config file without extension.
+// The default is "config".
+func WithFileName(name string) Option {
+	return func(l *Loader) {
+		l.fileName = name
+	}
+}
+
+// WithSearchPaths adds directories which are searched for config files
+// after the working directory. The files of all directories are merged,
+// the files of the working directory and then of the first given
+// directory take precedence.
+func WithSearchPaths(paths ...string) Option {
+	return func(l *Loader) {
+		l.paths = append(l.paths, paths...)
+	}
+}
+
+// WithFormat restricts the config files to the given format, e.g. "yaml",
+// "json" or "toml". By default, all of them are searched for.
+func WithFormat(format string) Option {
+	return func(l *Loader) {
+		l.format = format
+	}
+}
+
+// WithLogger sets the logger used to report config reloads and
+// their errors, see Watch.
+func WithLogger(logger logr.Logger) Option {
+	return func(l *Loader) {
+		l.logger = logger
+	}
+}
+
+// WithPollInterval makes Watch poll the config files for changes in the
+// given interval instead of using file system notifications.
+func WithPollInterval(interval time.Duration) Option {
+	return func(l *Loader) {
+		l.pollInterval = interval
+	}
+}
+
+// WithConfigFile sets the path of the config file, e.g. from a --config
+// flag. The file must exist and replaces the files of the search paths.
+// An empty path is ignored.
+func WithConfigFile(path string) Option {
+	return func(l *Loader) {
+		l.configFile = path
+	}
+}
+
+// WithEnvironment sets the environment of the overlay config file,
+// e.g. "production" for config.production.yaml. By default, it is taken
+// from the environment variable <PREFIX>_ENV or the key serverMode.
+func WithEnvironment(env string) Option {
+	return func(l *Loader) {
+		l.env = env
+	}
+}
+
+// WithTenantDir sets the directory of tenant config files, named by the
+// tenant ID, e.g. acme.yaml. They override the section tenants.<id> of the
+// config, see Loader.LoadTenant.
+func WithTenantDir(dir string) Option {
+	return func(l *Loader) {
+		l.tenantDir = dir
+	}
+}
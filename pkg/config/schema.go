@@ -0,0 +1,266 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// WriteSchemaJSON writes a JSON Schema of the config struct, e.g. to
+// validate the values of a Helm chart. Nested structs are nested objects;
+// each key has its type, default, description (see the `desc` tag) and
+// environment variable as extension x-env. The `validate` tags required,
+// min, max, url and oneof and the `required:"true"` tag are mapped to
+// their schema keywords, secrets (see Effective) are marked writeOnly.
+// Keys with a default are not required, as they always have a value.
+func (l *Loader) WriteSchemaJSON(w io.Writer, config any) error {
+	root := map[string]any{
+		"$schema":    schemaDraft,
+		"type":       "object",
+		"properties": map[string]any{},
+	}
+
+	for _, f := range fields(reflect.TypeOf(config)) {
+		parent := root
+		parts := strings.Split(f.key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			properties := parent["properties"].(map[string]any)
+			child, ok := properties[part].(map[string]any)
+			if !ok {
+				child = map[string]any{"type": "object", "properties": map[string]any{}}
+				properties[part] = child
+			}
+			parent = child
+		}
+
+		parent["properties"].(map[string]any)[parts[len(parts)-1]] = l.schemaOf(f)
+		if isRequired(f) {
+			required, _ := parent["required"].([]string)
+			parent["required"] = append(required, parts[len(parts)-1])
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(root)
+}
+
+// WriteSchemaMarkdown writes a Markdown table of the keys of the config
+// struct with their environment variable, type, default and description.
+func (l *Loader) WriteSchemaMarkdown(w io.Writer, config any) error {
+	if _, err := fmt.Fprintln(w, "| Key | Env | Type | Default | Required | Description |"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "|-----|-----|------|---------|----------|-------------|"); err != nil {
+		return err
+	}
+
+	for _, f := range fields(reflect.TypeOf(config)) {
+		def := f.Tag.Get("default")
+		if def != "" {
+			def = "`" + def + "`"
+		}
+
+		required := ""
+		if isRequired(f) {
+			required = "yes"
+		}
+
+		_, err := fmt.Fprintf(w, "| `%s` | `%s` | %s | %s | %s | %s |\n",
+			f.key, l.envNames(f)[0], typeName(f.Type), def, required, escapeMarkdown(f.Tag.Get("desc")))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// schemaOf returns the schema of the leaf field.
+func (l *Loader) schemaOf(f field) map[string]any {
+	schema := typeSchema(f.Type)
+	schema["x-env"] = l.envNames(f)[0]
+
+	if desc := f.Tag.Get("desc"); desc != "" {
+		schema["description"] = desc
+	}
+	if def, ok := f.Tag.Lookup("default"); ok {
+		schema["default"] = typedDefault(f, def)
+	}
+	if isSecret(f) {
+		schema["writeOnly"] = true
+	}
+
+	for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "min", "max":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			schema[limitKeyword(schema["type"], name)] = n
+		case "url", "uri", "http_url":
+			schema["format"] = "uri"
+		case "oneof":
+			values := strings.Fields(param)
+			enum := make([]any, len(values))
+			for i, value := range values {
+				enum[i] = typedScalar(f.Type, value)
+			}
+			schema["enum"] = enum
+		}
+	}
+
+	return schema
+}
+
+// limitKeyword returns the keyword of the min or max rule for the type.
+func limitKeyword(typ any, rule string) string {
+	switch typ {
+	case "string":
+		return rule + "Length"
+	case "array":
+		return rule + "Items"
+	case "object":
+		return rule + "Properties"
+	case "integer", "number":
+		if rule == "min" {
+			return "minimum"
+		}
+		return "maximum"
+	}
+
+	return rule
+}
+
+// durationPattern matches the durations of time.ParseDuration, e.g.
+// 1m30s. The format duration of JSON schema is ISO 8601, e.g. PT1M30S,
+// which viper does not parse.
+const durationPattern = `^[-+]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|μs|ms|s|m|h))+)$`
+
+func typeSchema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case reflect.TypeOf(time.Duration(0)):
+		return map[string]any{"type": "string", "pattern": durationPattern}
+	case reflect.TypeOf(time.Time{}):
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Interface:
+		return map[string]any{}
+	}
+
+	return map[string]any{"type": "string"}
+}
+
+// typeName returns the name of the type in the Markdown table.
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return "duration"
+	}
+
+	schema := typeSchema(t)
+	switch schema["type"] {
+	case "array":
+		return "list of " + typeName(t.Elem())
+	case "object":
+		return "map of " + typeName(t.Elem())
+	case nil:
+		return "any"
+	}
+
+	if format, ok := schema["format"].(string); ok {
+		return format
+	}
+
+	return schema["type"].(string)
+}
+
+// typedDefault converts the default tag to the JSON type of the field.
+func typedDefault(f field, value string) any {
+	t := f.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch typeSchema(t)["type"] {
+	case "array":
+		return strings.Split(value, ",")
+	case "object":
+		return defaultValue(f, value)
+	}
+
+	return typedScalar(t, value)
+}
+
+// typedScalar converts the value to the JSON type of a boolean or number
+// type. Values of other types and invalid values are kept as string.
+func typedScalar(t reflect.Type, value string) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch typeSchema(t)["type"] {
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+
+	return value
+}
+
+// isRequired reports whether the field is required and has no default.
+func isRequired(f field) bool {
+	if _, ok := f.Tag.Lookup("default"); ok {
+		return false
+	}
+
+	if f.Tag.Get("required") == "true" {
+		return true
+	}
+
+	for _, rule := range strings.Split(f.Tag.Get("validate"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
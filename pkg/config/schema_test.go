@@ -0,0 +1,95 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/config"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/db/postgres"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/db/redis"
+)
+
+type schemaConfig struct {
+	config.BaseConfig `mapstructure:",squash"`
+	Postgres          postgres.Config `mapstructure:"postgres"`
+	Redis             redis.Config    `mapstructure:"redis"`
+	OAuth             struct {
+		ServerUrl string `mapstructure:"serverUrl" validate:"required,url" desc:"OAuth server | issuer"`
+	} `mapstructure:"oAuth"`
+	Workers  int           `mapstructure:"workers" validate:"min=1,max=16" default:"4"`
+	Replicas int           `mapstructure:"replicas" validate:"oneof=1 3 5"`
+	Region   string        `mapstructure:"region" validate:"required,oneof=eu us" default:"eu"`
+	Timeout  time.Duration `mapstructure:"timeout" default:"1m30s"`
+}
+
+func TestLoader_WriteSchemaJSON(t *testing.T) {
+	b := new(bytes.Buffer)
+	require.NoError(t, config.NewLoader("example").WriteSchemaJSON(b, &schemaConfig{}))
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(b.Bytes(), &schema))
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", schema["$schema"])
+
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"type":        "integer",
+		"default":     float64(8080),
+		"description": "port the server listens on",
+		"x-env":       "EXAMPLE_LISTEN_PORT",
+	}, properties["listenPort"])
+	assert.Equal(t, map[string]any{
+		"type":    "integer",
+		"default": float64(4),
+		"minimum": float64(1),
+		"maximum": float64(16),
+		"x-env":   "EXAMPLE_WORKERS",
+	}, properties["workers"])
+
+	pg := properties["postgres"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, true, pg["password"].(map[string]any)["writeOnly"])
+	assert.Equal(t, map[string]any{"sslmode": "require"}, pg["params"].(map[string]any)["default"])
+
+	assert.Equal(t, []any{float64(1), float64(3), float64(5)}, properties["replicas"].(map[string]any)["enum"])
+	assert.Equal(t, []any{"eu", "us"}, properties["region"].(map[string]any)["enum"])
+
+	// durations are Go durations, not ISO 8601 ones
+	timeout := properties["timeout"].(map[string]any)
+	assert.Equal(t, "string", timeout["type"])
+	assert.NotContains(t, timeout, "format")
+	pattern := regexp.MustCompile(timeout["pattern"].(string))
+	for _, valid := range []string{"1m30s", "0", "-1.5h", "300ms", "2µs"} {
+		assert.True(t, pattern.MatchString(valid), valid)
+	}
+	for _, invalid := range []string{"PT1M30S", "10", "1d", ""} {
+		assert.False(t, pattern.MatchString(invalid), invalid)
+	}
+
+	// keys with default are not required
+	assert.NotContains(t, schema, "required")
+	rd := properties["redis"].(map[string]any)
+	assert.NotContains(t, rd, "required")
+	assert.Equal(t, "EXAMPLE_REDIS_IS_CLUSTER", rd["properties"].(map[string]any)["isCluster"].(map[string]any)["x-env"])
+
+	oauth := properties["oAuth"].(map[string]any)
+	assert.Equal(t, []any{"serverUrl"}, oauth["required"])
+	assert.Equal(t, "uri", oauth["properties"].(map[string]any)["serverUrl"].(map[string]any)["format"])
+}
+
+func TestLoader_WriteSchemaMarkdown(t *testing.T) {
+	b := new(bytes.Buffer)
+	require.NoError(t, config.NewLoader("example").WriteSchemaMarkdown(b, &schemaConfig{}))
+
+	md := b.String()
+	assert.Contains(t, md, "| Key | Env | Type | Default | Required | Description |\n")
+	assert.Contains(t, md, "| `listenPort` | `EXAMPLE_LISTEN_PORT` | integer | `8080` |  | port the server listens on |\n")
+	assert.Contains(t, md, "| `postgres.params` | `EXAMPLE_POSTGRES_PARAMS` | map of string | `sslmode:require` |  | connection parameters, e.g. sslmode:require |\n")
+	assert.Contains(t, md, "| `redis.hosts` | `EXAMPLE_REDIS_HOSTS` | string | `127.0.0.1` |  |")
+	assert.Contains(t, md, "| `timeout` | `EXAMPLE_TIMEOUT` | duration | `1m30s` |  |")
+	assert.Contains(t, md, "| `oAuth.serverUrl` | `EXAMPLE_OAUTH_SERVERURL` | string |  | yes | OAuth server \\| issuer |\n")
+}
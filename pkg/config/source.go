@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Reference prefixes of config values, see Loader.Load.
+const (
+	filePrefix = "file://"
+	envPrefix  = "env://"
+)
+
+// SourceKind is the kind of source a config value was read from.
+type SourceKind string
+
+const (
+	SourceUnset   SourceKind = "unset"
+	SourceDefault SourceKind = "default"
+	SourceFile    SourceKind = "file"
+	SourceEnv     SourceKind = "env"
+	SourceFlag    SourceKind = "flag"
+)
+
+// Source describes where the value of a config key came from.
+type Source struct {
+	Kind SourceKind `json:"kind"`
+
+	// Name is the config file, environment variable or flag of the value.
+	Name string `json:"name,omitempty"`
+
+	// Ref is the file or environment variable the value was resolved
+	// from, e.g. the file of a <VAR>_FILE variable or a file:// value.
+	Ref string `json:"ref,omitempty"`
+}
+
+func (s Source) String() string {
+	str := string(s.Kind)
+	if s.Name != "" {
+		str += " " + s.Name
+	}
+	if s.Ref != "" {
+		str += " -> " + s.Ref
+	}
+
+	return str
+}
+
+// Sources returns the source of each key read by the last successful Load.
+func (l *Loader) Sources() map[string]Source {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	sources := make(map[string]Source, len(l.sources))
+	for key, source := range l.sources {
+		sources[key] = source
+	}
+
+	return sources
+}
+
+// resolve determines the source of each key and resolves secret files
+// and references. A key without environment variable is read from the
+// file named by the variable with suffix _FILE, e.g.
+// EXAMPLE_POSTGRES_PASSWORD_FILE. String values of the form
+// file://<path> are replaced with the content of the file, values of
+// the form env://<name> with the environment variable. Trailing line
+// breaks of files are removed.
+//
+// It returns the source of each key and the read secret files.
+func (l *Loader) resolve(fs []field, defaulted map[string]bool) (map[string]Source, []string, error) {
+	sources := make(map[string]Source, len(fs))
+	var secretFiles []string
+
+	for _, f := range fs {
+		source := l.source(f, defaulted)
+
+		if source.Kind == SourceEnv && strings.HasSuffix(source.Name, "_FILE") {
+			path := os.Getenv(source.Name)
+			value, err := readSecret(path)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read %s from %s: %w", f.key, source.Name, err)
+			}
+			source.Ref = path
+			secretFiles = append(secretFiles, path)
+			l.viper.Set(f.key, value)
+		}
+
+		if s, ok := l.viper.Get(f.key).(string); ok && isReference(s) {
+			value, file, err := dereference(s)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to resolve %s: %w", f.key, err)
+			}
+			source.Ref = s
+			l.viper.Set(f.key, value)
+			if file != "" {
+				secretFiles = append(secretFiles, file)
+			}
+		}
+
+		sources[f.key] = source
+	}
+
+	for i, file := range secretFiles {
+		if abs, err := filepath.Abs(file); err == nil {
+			secretFiles[i] = abs
+		}
+	}
+
+	return sources, secretFiles, nil
+}
+
+// source returns where the value of the field is read from, following
+// the precedence of viper.
+func (l *Loader) source(f field, defaulted map[string]bool) Source {
+	if flag, ok := l.changedFlag(f); ok {
+		return Source{Kind: SourceFlag, Name: "--" + flag.Name}
+	}
+
+	for _, name := range l.envNames(f) {
+		if _, ok := os.LookupEnv(name); ok {
+			return Source{Kind: SourceEnv, Name: name}
+		}
+		if _, ok := os.LookupEnv(name + "_FILE"); ok {
+			return Source{Kind: SourceEnv, Name: name + "_FILE"}
+		}
+	}
+
+	if file, ok := l.fileOf(f.key); ok {
+		return Source{Kind: SourceFile, Name: file}
+	}
+
+	if defaulted[strings.ToLower(f.key)] {
+		return Source{Kind: SourceDefault}
+	}
+
+	return Source{Kind: SourceUnset}
+}
+
+func isReference(value string) bool {
+	return strings.HasPrefix(value, filePrefix) || strings.HasPrefix(value, envPrefix)
+}
+
+// dereference returns the value of a file:// or env:// reference and the
+// read file, if any. Other values are returned unchanged.
+func dereference(value string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(value, filePrefix):
+		path := strings.TrimPrefix(value, filePrefix)
+		resolved, err := readSecret(path)
+		return resolved, path, err
+	case strings.HasPrefix(value, envPrefix):
+		name := strings.TrimPrefix(value, envPrefix)
+		resolved, ok := os.LookupEnv(name)
+		if !ok {
+			return "", "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return resolved, "", nil
+	}
+
+	return value, "", nil
+}
+
+func readSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
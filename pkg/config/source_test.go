@@ -0,0 +1,80 @@
+package config_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/config"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/db/postgres"
+)
+
+type secretConfig struct {
+	config.BaseConfig `mapstructure:",squash"`
+	Postgres          postgres.Config `mapstructure:"postgres"`
+	OAuth             struct {
+		ClientId     string `mapstructure:"clientId"`
+		ClientSecret string `mapstructure:"clientSecret"`
+	} `mapstructure:"oAuth"`
+}
+
+func TestLoader_Secrets(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "pg", "s3cret\n")
+	writeFile(t, dir, "oauth", "client-secret")
+	writeFile(t, dir, "config.yaml", "oAuth:\n  clientId: env://CLIENT_ID\n  clientSecret: file://"+filepath.Join(dir, "oauth")+"\n")
+	t.Setenv("SECRETS_POSTGRES_PASSWORD_FILE", filepath.Join(dir, "pg"))
+	t.Setenv("CLIENT_ID", "client")
+	t.Setenv("SECRETS_POSTGRES_USER", "admin")
+
+	var cfg secretConfig
+	loader := config.NewLoader("secrets", config.WithSearchPaths(dir))
+	require.NoError(t, loader.Load(&cfg, nil))
+
+	assert.Equal(t, "s3cret", cfg.Postgres.Password)
+	assert.Equal(t, "admin", cfg.Postgres.User)
+	assert.Equal(t, "client", cfg.OAuth.ClientId)
+	assert.Equal(t, "client-secret", cfg.OAuth.ClientSecret)
+
+	file := filepath.Join(dir, "config.yaml")
+	sources := loader.Sources()
+	assert.Equal(t, config.Source{Kind: config.SourceEnv, Name: "SECRETS_POSTGRES_PASSWORD_FILE", Ref: filepath.Join(dir, "pg")}, sources["postgres.password"])
+	assert.Equal(t, config.Source{Kind: config.SourceEnv, Name: "SECRETS_POSTGRES_USER"}, sources["postgres.user"])
+	assert.Equal(t, config.Source{Kind: config.SourceFile, Name: file, Ref: "env://CLIENT_ID"}, sources["oAuth.clientId"])
+	assert.Equal(t, config.Source{Kind: config.SourceDefault}, sources["postgres.host"])
+	assert.Equal(t, "env SECRETS_POSTGRES_PASSWORD_FILE -> "+filepath.Join(dir, "pg"), sources["postgres.password"].String())
+}
+
+func TestLoader_SecretErrors(t *testing.T) {
+	t.Setenv("SECRETS_POSTGRES_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	var cfg secretConfig
+	err := config.NewLoader("secrets").Load(&cfg, nil)
+	assert.ErrorContains(t, err, "failed to read postgres.password from SECRETS_POSTGRES_PASSWORD_FILE")
+
+	err = config.NewLoader("other").Load(&cfg, map[string]any{"oAuth.clientId": "env://UNSET_CLIENT_ID"})
+	assert.EqualError(t, err, "failed to resolve oAuth.clientId: environment variable UNSET_CLIENT_ID is not set")
+}
+
+func TestWatch_SecretFile(t *testing.T) {
+	dir := t.TempDir()
+	secrets := t.TempDir()
+	writeFile(t, dir, "config.yaml", "logLevel: info\n")
+	writeFile(t, secrets, "pg", "old")
+	t.Setenv("SECRETS_POSTGRES_PASSWORD_FILE", filepath.Join(secrets, "pg"))
+
+	loader := config.NewLoader("secrets", config.WithSearchPaths(dir))
+	watcher, err := config.Watch[secretConfig](loader, nil)
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	assert.Equal(t, "old", watcher.Get().Postgres.Password)
+
+	writeFile(t, secrets, "pg", "new")
+	assert.Eventually(t, func() bool {
+		return watcher.Get().Postgres.Password == "new"
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// tenantsKey is the config section of the tenant overrides.
+const tenantsKey = "tenants"
+
+// readTenants returns the overrides of each tenant and the read tenant
+// files. The overrides of a tenant are the section tenants.<id> of the
+// config, merged with the file <id>.<ext> of the tenant directory (see
+// WithTenantDir). Tenant IDs are lower case, as viper keys are case
+// insensitive.
+func (l *Loader) readTenants() (map[string]map[string]any, []string, error) {
+	tenants := map[string]map[string]any{}
+	if section, ok := l.viper.Get(tenantsKey).(map[string]any); ok {
+		for id, overrides := range section {
+			if m, ok := overrides.(map[string]any); ok {
+				// copied, as the references are resolved in place
+				tenants[strings.ToLower(id)] = mergeSettings(m)
+			}
+		}
+	}
+
+	if l.tenantDir == "" {
+		return tenants, nil, nil
+	}
+
+	entries, err := os.ReadDir(l.tenantDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tenants, nil, nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("failed to read tenant directory: %w", err)
+	}
+
+	dir, err := filepath.Abs(l.tenantDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the directory itself is watched for new tenants
+	files := []string{dir}
+	for _, entry := range entries {
+		ext := strings.TrimPrefix(filepath.Ext(entry.Name()), ".")
+		if entry.IsDir() || !slices.Contains(formats, ext) {
+			continue
+		}
+
+		file := filepath.Join(dir, entry.Name())
+		v := viper.New()
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, nil, fmt.Errorf("error read in tenant file %s: %w", file, err)
+		}
+
+		id := strings.ToLower(strings.TrimSuffix(entry.Name(), "."+ext))
+		tenants[id] = mergeSettings(tenants[id], v.AllSettings())
+		files = append(files, file)
+	}
+
+	return tenants, files, nil
+}
+
+// dereferenceTenants replaces the file:// and env:// references of the
+// tenant overrides with their values, like resolve does for the config
+// of the service. It returns the read secret files.
+func dereferenceTenants(tenants map[string]map[string]any) ([]string, error) {
+	var secretFiles []string
+	for id, overrides := range tenants {
+		files, err := dereferenceSettings(overrides, tenantsKey+"."+id)
+		if err != nil {
+			return nil, err
+		}
+		secretFiles = append(secretFiles, files...)
+	}
+
+	for i, file := range secretFiles {
+		if abs, err := filepath.Abs(file); err == nil {
+			secretFiles[i] = abs
+		}
+	}
+
+	return secretFiles, nil
+}
+
+func dereferenceSettings(settings map[string]any, prefix string) ([]string, error) {
+	var secretFiles []string
+	for key, value := range settings {
+		switch v := value.(type) {
+		case map[string]any:
+			files, err := dereferenceSettings(v, prefix+"."+key)
+			if err != nil {
+				return nil, err
+			}
+			secretFiles = append(secretFiles, files...)
+		case string:
+			if !isReference(v) {
+				continue
+			}
+			resolved, file, err := dereference(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %s.%s: %w", prefix, key, err)
+			}
+			settings[key] = resolved
+			if file != "" {
+				secretFiles = append(secretFiles, file)
+			}
+		}
+	}
+
+	return secretFiles, nil
+}
+
+// Tenants returns the IDs of the tenants with overrides, read by the last
+// successful Load.
+func (l *Loader) Tenants() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	ids := make([]string, 0, len(l.tenants))
+	for id := range l.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// LoadTenant unmarshals the config of the last successful Load merged with
+// the overrides of the tenant into config and validates it. Without
+// overrides, config gets the values of the service. The overrides take
+// precedence over all other sources, e.g. with the config file
+//
+//	ocmAddress: https://ocm.example.com
+//	tenants:
+//	  acme:
+//	    ocmAddress: https://ocm.acme.example.com
+//
+// tenant acme gets its own OCM address, all other tenants the default.
+// file:// and env:// references in the overrides are resolved by Load.
+func (l *Loader) LoadTenant(id string, config any) error {
+	l.mu.RLock()
+	settings, overrides := l.settings, l.tenants[strings.ToLower(id)]
+	l.mu.RUnlock()
+
+	v := viper.New()
+	if err := v.MergeConfigMap(mergeSettings(settings, overrides)); err != nil {
+		return err
+	}
+	if err := v.Unmarshal(config); err != nil {
+		return err
+	}
+
+	if err := l.validate(config); err != nil {
+		return fmt.Errorf("invalid config of tenant %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// mergeSettings deep merges the settings into a new map, later ones take
+// precedence. The given settings are not modified, unlike with
+// viper.MergeConfigMap.
+func mergeSettings(settings ...map[string]any) map[string]any {
+	merged := map[string]any{}
+	for _, s := range settings {
+		mergeInto(merged, s)
+	}
+
+	return merged
+}
+
+func mergeInto(dst, src map[string]any) {
+	for key, value := range src {
+		key = strings.ToLower(key)
+		if m, ok := value.(map[string]any); ok {
+			nested, ok := dst[key].(map[string]any)
+			if !ok {
+				nested = map[string]any{}
+				dst[key] = nested
+			}
+			mergeInto(nested, m)
+			continue
+		}
+		dst[key] = value
+	}
+}
@@ -0,0 +1,133 @@
+package config_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/config"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+)
+
+type tenantConfig struct {
+	config.BaseConfig `mapstructure:",squash"`
+	OcmAddress        string `mapstructure:"ocmAddress" validate:"required,url"`
+	Cache             struct {
+		Namespace string `mapstructure:"namespace"`
+		TTL       int    `mapstructure:"ttl"`
+	} `mapstructure:"cache"`
+}
+
+func TestLoader_LoadTenant(t *testing.T) {
+	dir, tenants := t.TempDir(), t.TempDir()
+	writeFile(t, dir, "config.yaml", `
+ocmAddress: https://ocm.example.com
+cache:
+  namespace: shared
+  ttl: 60
+tenants:
+  acme:
+    ocmAddress: https://ocm.acme.example.com
+    cache:
+      namespace: acme
+  Globex:
+    cache:
+      ttl: 10
+`)
+	writeFile(t, tenants, "acme.json", `{"cache": {"ttl": 30}}`)
+	writeFile(t, tenants, "initech.yaml", "ocmAddress: invalid\n")
+	t.Setenv("TENANTS_LOG_LEVEL", "debug")
+
+	loader := config.NewLoader("tenants", config.WithSearchPaths(dir), config.WithTenantDir(tenants))
+	var cfg tenantConfig
+	require.NoError(t, loader.Load(&cfg, nil))
+	assert.Equal(t, []string{"acme", "globex", "initech"}, loader.Tenants())
+
+	var acme tenantConfig
+	require.NoError(t, loader.LoadTenant("acme", &acme))
+	assert.Equal(t, "https://ocm.acme.example.com", acme.OcmAddress)
+	assert.Equal(t, "acme", acme.Cache.Namespace)
+	assert.Equal(t, 30, acme.Cache.TTL)
+	assert.Equal(t, "debug", acme.LogLevel)
+
+	var globex tenantConfig
+	require.NoError(t, loader.LoadTenant("Globex", &globex))
+	assert.Equal(t, "https://ocm.example.com", globex.OcmAddress)
+	assert.Equal(t, "shared", globex.Cache.Namespace)
+	assert.Equal(t, 10, globex.Cache.TTL)
+
+	var other tenantConfig
+	require.NoError(t, loader.LoadTenant("other", &other))
+	assert.Equal(t, cfg, other)
+
+	var initech tenantConfig
+	assert.ErrorContains(t, loader.LoadTenant("initech", &initech), "invalid config of tenant initech: invalid config: ocmAddress")
+}
+
+func testWatchTenants(t *testing.T, opts ...config.Option) {
+	dir, tenants := t.TempDir(), t.TempDir()
+	writeFile(t, dir, "config.yaml", "ocmAddress: https://ocm.example.com\n")
+
+	loader := config.NewLoader("tenants", append(opts,
+		config.WithSearchPaths(dir),
+		config.WithTenantDir(tenants),
+	)...)
+	watcher, err := config.Watch[tenantConfig](loader, nil)
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	c := ctx.WithTenantID(context.Background(), "acme")
+	assert.Equal(t, "https://ocm.example.com", watcher.Resolve(c).OcmAddress)
+	assert.Same(t, watcher.Get(), watcher.Resolve(context.Background()))
+
+	// new tenant file
+	writeFile(t, tenants, "acme.yaml", "ocmAddress: https://ocm.acme.example.com\n")
+	assert.Eventually(t, func() bool {
+		return watcher.Resolve(c).OcmAddress == "https://ocm.acme.example.com"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// changed tenant section
+	writeFile(t, dir, "config.yaml", "ocmAddress: https://ocm.example.com\ntenants:\n  globex:\n    ocmAddress: https://ocm.globex.example.com\n")
+	assert.Eventually(t, func() bool {
+		return watcher.Tenant("globex").OcmAddress == "https://ocm.globex.example.com"
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "https://ocm.acme.example.com", watcher.Tenant("acme").OcmAddress)
+}
+
+func TestWatch_TenantsNotify(t *testing.T) {
+	testWatchTenants(t)
+}
+
+func TestWatch_TenantsPoll(t *testing.T) {
+	testWatchTenants(t, config.WithPollInterval(20*time.Millisecond))
+}
+
+func TestWatch_TenantSecretFile(t *testing.T) {
+	dir, tenants, secrets := t.TempDir(), t.TempDir(), t.TempDir()
+	writeFile(t, secrets, "acme", "https://ocm.acme.example.com\n")
+	writeFile(t, dir, "config.yaml", "ocmAddress: https://ocm.example.com\ntenants:\n  acme:\n    ocmAddress: file://"+filepath.Join(secrets, "acme")+"\n")
+	writeFile(t, tenants, "globex.yaml", "cache:\n  namespace: env://GLOBEX_NAMESPACE\n")
+	t.Setenv("GLOBEX_NAMESPACE", "globex")
+
+	loader := config.NewLoader("tenants", config.WithSearchPaths(dir), config.WithTenantDir(tenants))
+	watcher, err := config.Watch[tenantConfig](loader, nil)
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	assert.Equal(t, "https://ocm.acme.example.com", watcher.Tenant("acme").OcmAddress)
+	assert.Equal(t, "globex", watcher.Tenant("globex").Cache.Namespace)
+
+	writeFile(t, secrets, "acme", "https://ocm2.acme.example.com\n")
+	assert.Eventually(t, func() bool {
+		return watcher.Tenant("acme").OcmAddress == "https://ocm2.acme.example.com"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// unresolvable references fail the load
+	writeFile(t, tenants, "initech.yaml", "ocmAddress: env://INITECH_OCM_ADDRESS\n")
+	var cfg tenantConfig
+	assert.ErrorContains(t, loader.Load(&cfg, nil), "failed to resolve tenants.initech.ocmaddress")
+}
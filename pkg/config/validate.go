@@ -0,0 +1,153 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// structValidator checks the `validate` tags of config structs.
+var structValidator = validator.New(validator.WithRequiredStructEnabled())
+
+// Validator can be implemented by config structs to check the loaded
+// values, e.g. constraints between several keys.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError describes an invalid config value.
+type FieldError struct {
+	// Key is the config key, e.g. "postgres.host".
+	Key string
+
+	// Env is the environment variable of the key, e.g. "EXAMPLE_POSTGRES_HOST".
+	Env string
+
+	// Rule is the failed rule, e.g. "required" or "min".
+	Rule string
+
+	// Param is the parameter of the rule, e.g. "1" for min=1.
+	Param string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s (%s) %s", e.Key, e.Env, e.reason())
+}
+
+func (e FieldError) reason() string {
+	switch e.Rule {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		return "must be at least " + e.Param
+	case "max", "lte":
+		return "must be at most " + e.Param
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(e.Param), ", ")
+	case "url", "uri", "http_url":
+		return "must be a valid URL"
+	}
+
+	if e.Param != "" {
+		return fmt.Sprintf("failed on %s=%s", e.Rule, e.Param)
+	}
+
+	return "failed on " + e.Rule
+}
+
+// ValidationError lists all invalid values of a loaded config.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	b := new(strings.Builder)
+	b.WriteString("invalid config: ")
+	for i, f := range e.Fields {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(f.Error())
+	}
+
+	return b.String()
+}
+
+// validate checks the loaded config. It checks the `validate` tags of
+// go-playground/validator (e.g. `validate:"required,min=1,url"`) and the
+// `required:"true"` tags of envconfig, and reports all invalid keys in
+// one *ValidationError. If the tags are satisfied and the config
+// implements Validator, its Validate method is called.
+func (l *Loader) validate(config any) error {
+	fs := fields(reflect.TypeOf(config))
+	if fs == nil {
+		return nil
+	}
+
+	var invalid []FieldError
+	seen := map[string]bool{}
+	add := func(key, env, rule, param string) {
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		invalid = append(invalid, FieldError{Key: key, Env: env, Rule: rule, Param: param})
+	}
+
+	err := structValidator.Struct(config)
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		byPath := make(map[string]field, len(fs))
+		for _, f := range fs {
+			byPath[f.path] = f
+		}
+
+		for _, fe := range verrs {
+			key, env := l.fieldKey(byPath, fe.StructNamespace())
+			add(key, env, fe.Tag(), fe.Param())
+		}
+	} else if err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(config)
+	for _, f := range fs {
+		if f.Tag.Get("required") != "true" {
+			continue
+		}
+		if fv, ok := f.value(v); !ok || fv.IsZero() {
+			add(f.key, l.envNames(f)[0], "required", "")
+		}
+	}
+
+	if len(invalid) > 0 {
+		return &ValidationError{Fields: invalid}
+	}
+
+	if v, ok := config.(Validator); ok {
+		return v.Validate()
+	}
+
+	return nil
+}
+
+// fieldKey returns the config key and environment variable of the
+// validator namespace, e.g. "testConfig.Postgres.Host". Elements of
+// slices and maps keep their index, e.g. "hosts[0]".
+func (l *Loader) fieldKey(byPath map[string]field, namespace string) (string, string) {
+	_, path, _ := strings.Cut(namespace, ".")
+
+	name, rest := path, ""
+	if i := strings.IndexByte(path, '['); i >= 0 {
+		name, rest = path[:i], path[i:]
+	}
+
+	if f, ok := byPath[name]; ok {
+		return f.key + rest, l.envNames(f)[0]
+	}
+
+	return path, l.envName(name)
+}
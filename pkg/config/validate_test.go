@@ -0,0 +1,55 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/config"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/db/postgres"
+)
+
+type validatedConfig struct {
+	config.BaseConfig `mapstructure:",squash"`
+	Postgres          postgres.Config `mapstructure:"postgres"`
+	Port              int             `mapstructure:"port" validate:"min=1"`
+	OAuth             struct {
+		ServerUrl string `mapstructure:"serverUrl" validate:"required,url"`
+		ClientId  string `mapstructure:"clientId" required:"true"`
+	} `mapstructure:"oAuth"`
+	Hosts []string `mapstructure:"hosts" validate:"dive,hostname"`
+}
+
+func TestLoader_Validate(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "oAuth:\n  serverUrl: not a url\nhosts: [localhost, in_valid]\n")
+
+	var cfg validatedConfig
+	err := config.NewLoader("valid", config.WithSearchPaths(dir)).Load(&cfg, nil)
+
+	var verr *config.ValidationError
+	require.True(t, errors.As(err, &verr), err)
+	assert.Equal(t, []config.FieldError{
+		{Key: "port", Env: "VALID_PORT", Rule: "min", Param: "1"},
+		{Key: "oAuth.serverUrl", Env: "VALID_OAUTH_SERVERURL", Rule: "url"},
+		{Key: "hosts[1]", Env: "VALID_HOSTS", Rule: "hostname"},
+		{Key: "oAuth.clientId", Env: "VALID_OAUTH_CLIENTID", Rule: "required"},
+	}, verr.Fields)
+	assert.EqualError(t, err, "invalid config: "+
+		"port (VALID_PORT) must be at least 1; "+
+		"oAuth.serverUrl (VALID_OAUTH_SERVERURL) must be a valid URL; "+
+		"hosts[1] (VALID_HOSTS) failed on hostname; "+
+		"oAuth.clientId (VALID_OAUTH_CLIENTID) is required")
+}
+
+func TestLoader_ValidateValid(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "oAuth:\n  serverUrl: https://auth.example.com\n  clientId: client\n")
+	t.Setenv("VALID_PORT", "80")
+
+	var cfg validatedConfig
+	require.NoError(t, config.NewLoader("valid", config.WithSearchPaths(dir)).Load(&cfg, map[string]any{"port": 0}))
+	assert.Equal(t, 80, cfg.Port)
+}
@@ -0,0 +1,325 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+)
+
+// debounce is the time to wait for further file events before reloading,
+// as editors and ConfigMap updates produce several events per change.
+const debounce = 100 * time.Millisecond
+
+// Watcher holds the current configuration of type T and reloads it when
+// the config file changes. Use Watch to create a Watcher.
+type Watcher[T any] struct {
+	loader   *Loader
+	defaults map[string]any
+
+	current atomic.Pointer[snapshot[T]]
+
+	// reloadMu serializes reloads, as viper is not safe for concurrent use.
+	reloadMu sync.Mutex
+
+	subsMu      sync.Mutex
+	subscribers map[int]func(old, new *T)
+	nextSubID   int
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// snapshot is the configuration of the service and its tenants of a load.
+type snapshot[T any] struct {
+	config  *T
+	tenants map[string]*T
+}
+
+// Watch loads the configuration like Loader.Load and watches the config
+// file for changes. On change, the configuration is loaded into a fresh
+// copy of T, validated and atomically swapped, and subscribers are
+// notified with the old and new values. If loading or validation fails,
+// the old values are kept and the error is logged with the logger of
+// the loader (see WithLogger). The configurations of the tenants (see
+// Tenant and Resolve) are reloaded with it.
+//
+// Changes are detected with file system notifications, which also cover
+// the symlink swap of mounted Kubernetes ConfigMaps. Use WithPollInterval
+// to poll the file instead, e.g. on file systems without notifications.
+//
+// Example:
+//
+//	watcher, err := config.Watch[ExampleConfig](config.NewLoader("EXAMPLE"), getDefaults())
+//	...
+//	defer watcher.Close()
+//	watcher.Subscribe(func(old, new *ExampleConfig) {
+//		if old.LogLevel != new.LogLevel { ... }
+//	})
+func Watch[T any](loader *Loader, defaults map[string]any) (*Watcher[T], error) {
+	w := &Watcher[T]{
+		loader:      loader,
+		defaults:    defaults,
+		subscribers: map[int]func(old, new *T){},
+		done:        make(chan struct{}),
+	}
+
+	s, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	w.current.Store(s)
+
+	files := w.files()
+	if loader.pollInterval > 0 {
+		w.wg.Add(1)
+		go w.poll(files, signature(files))
+		return w, nil
+	}
+
+	if err := w.notify(files); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Get returns the current configuration. The returned value must not be
+// modified, as it is shared by all callers.
+func (w *Watcher[T]) Get() *T {
+	return w.current.Load().config
+}
+
+// Tenant returns the current configuration of the tenant, see
+// Loader.LoadTenant. Tenants without overrides get the configuration of
+// Get. The returned value must not be modified.
+func (w *Watcher[T]) Tenant(id string) *T {
+	s := w.current.Load()
+	if cfg, ok := s.tenants[strings.ToLower(id)]; ok {
+		return cfg
+	}
+
+	return s.config
+}
+
+// Resolve returns the current configuration of the tenant of the context
+// (see ctx.WithTenantID), or the configuration of Get if it has none. In
+// gin handlers, pass the context of the request:
+//
+//	cfg := watcher.Resolve(c.Request.Context())
+func (w *Watcher[T]) Resolve(c context.Context) *T {
+	return w.Tenant(ctx.GetTenantID(c))
+}
+
+// Subscribe registers fn to be called with the old and new configuration
+// after every successful reload. It returns a function which removes the
+// subscription.
+func (w *Watcher[T]) Subscribe(fn func(old, new *T)) (unsubscribe func()) {
+	w.subsMu.Lock()
+	defer w.subsMu.Unlock()
+
+	id := w.nextSubID
+	w.nextSubID++
+	w.subscribers[id] = fn
+
+	return func() {
+		w.subsMu.Lock()
+		defer w.subsMu.Unlock()
+		delete(w.subscribers, id)
+	}
+}
+
+// Reload loads the configuration and swaps it in if it is valid. It is
+// called on file changes, but can also be called directly, e.g. on SIGHUP.
+// On error, the current configuration is kept.
+func (w *Watcher[T]) Reload() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	s, err := w.load()
+	if err != nil {
+		w.loader.logger.Error(err, "config reload failed, keeping current config")
+		return err
+	}
+
+	old := w.current.Swap(s).config
+	cfg := s.config
+	w.loader.logger.Info("config reloaded", "files", w.loader.files())
+
+	w.subsMu.Lock()
+	subscribers := make([]func(old, new *T), 0, len(w.subscribers))
+	for _, fn := range w.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	w.subsMu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(old, cfg)
+	}
+
+	return nil
+}
+
+// Close stops watching the config file.
+func (w *Watcher[T]) Close() {
+	w.closeOnce.Do(func() {
+		close(w.done)
+	})
+	w.wg.Wait()
+}
+
+func (w *Watcher[T]) load() (*snapshot[T], error) {
+	s := &snapshot[T]{config: new(T), tenants: map[string]*T{}}
+	if err := w.loader.Load(s.config, w.defaults); err != nil {
+		return nil, err
+	}
+
+	for _, id := range w.loader.Tenants() {
+		cfg := new(T)
+		if err := w.loader.LoadTenant(id, cfg); err != nil {
+			return nil, err
+		}
+		s.tenants[id] = cfg
+	}
+
+	return s, nil
+}
+
+// files returns the files of the loader to watch, including config files
+// which don't exist yet.
+func (w *Watcher[T]) files() []string {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	return w.loader.watchFiles()
+}
+
+// notify watches the directories of the config files, so atomic saves,
+// ConfigMap symlink swaps and new files are noticed. Directories of files
+// which are read after a reload, e.g. new secret files, are added;
+// directories which don't exist are skipped.
+func (w *Watcher[T]) notify(files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	dirs := map[string]bool{}
+	watchDirs := func(files []string) error {
+		for _, file := range files {
+			dir := filepath.Dir(file)
+			if info, err := os.Stat(file); err == nil && info.IsDir() {
+				dir = file
+			}
+			if dirs[dir] {
+				continue
+			}
+			if _, err := os.Stat(dir); os.IsNotExist(err) {
+				continue
+			}
+			if err := watcher.Add(dir); err != nil {
+				return fmt.Errorf("failed to watch config directory %s: %w", dir, err)
+			}
+			dirs[dir] = true
+		}
+		return nil
+	}
+
+	if err := watchDirs(files); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+
+	sig := signature(files)
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer watcher.Close()
+
+		timer := time.NewTimer(debounce)
+		timer.Stop()
+
+		for {
+			select {
+			case <-w.done:
+				timer.Stop()
+				return
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				timer.Reset(debounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				w.loader.logger.Error(err, "config watcher error")
+			case <-timer.C:
+				if current := signature(files); current != sig {
+					sig = current
+					if w.Reload() == nil {
+						files = w.files()
+						if err := watchDirs(files); err != nil {
+							w.loader.logger.Error(err, "config watcher error")
+						}
+						sig = signature(files)
+					}
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// poll checks the config files for changes in the poll interval.
+func (w *Watcher[T]) poll(files []string, sig string) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.loader.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			if current := signature(files); current != sig {
+				_ = w.Reload()
+				files = w.files()
+				sig = signature(files)
+			}
+		}
+	}
+}
+
+// signature identifies the state of the files by their resolved path,
+// size and modification time. Missing files are part of it, so their
+// creation changes it.
+func signature(files []string) string {
+	var sig string
+	for _, file := range files {
+		resolved, _ := filepath.EvalSymlinks(file)
+		sig += file + "|" + resolved
+		if info, err := os.Stat(file); err == nil {
+			sig += fmt.Sprintf("|%d|%d", info.Size(), info.ModTime().UnixNano())
+		}
+		sig += "\n"
+	}
+
+	return sig
+}
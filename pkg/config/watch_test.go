@@ -0,0 +1,159 @@
+package config_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/config"
+)
+
+type watchConfig struct {
+	config.BaseConfig `mapstructure:",squash"`
+	RateLimit         int `mapstructure:"rateLimit"`
+}
+
+func (c *watchConfig) Validate() error {
+	if c.RateLimit < 0 {
+		return fmt.Errorf("rateLimit must not be negative")
+	}
+	return nil
+}
+
+type changes struct {
+	mu   sync.Mutex
+	news []int
+}
+
+func (c *changes) add(old, new *watchConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.news = append(c.news, new.RateLimit)
+}
+
+func (c *changes) get() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]int(nil), c.news...)
+}
+
+func testWatch(t *testing.T, opts ...config.Option) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "rateLimit: 10\nlogLevel: info\n")
+
+	loader := config.NewLoader("watch", append(opts, config.WithSearchPaths(dir))...)
+	watcher, err := config.Watch[watchConfig](loader, nil)
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	assert.Equal(t, 10, watcher.Get().RateLimit)
+
+	var c changes
+	watcher.Subscribe(c.add)
+
+	writeFile(t, dir, "config.yaml", "rateLimit: 20\nlogLevel: debug\n")
+	assert.Eventually(t, func() bool {
+		return watcher.Get().RateLimit == 20
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "debug", watcher.Get().LogLevel)
+	assert.Equal(t, []int{20}, c.get())
+
+	// invalid config keeps the old values
+	writeFile(t, dir, "config.yaml", "rateLimit: -1\n")
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, 20, watcher.Get().RateLimit)
+	assert.Equal(t, []int{20}, c.get())
+}
+
+func TestWatch_Notify(t *testing.T) {
+	testWatch(t)
+}
+
+func TestWatch_Poll(t *testing.T) {
+	testWatch(t, config.WithPollInterval(20*time.Millisecond))
+}
+
+func testWatchNewOverlay(t *testing.T, opts ...config.Option) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "rateLimit: 10\n")
+
+	loader := config.NewLoader("watch", append(opts, config.WithSearchPaths(dir), config.WithEnvironment("staging"))...)
+	watcher, err := config.Watch[watchConfig](loader, nil)
+	require.NoError(t, err)
+	defer watcher.Close()
+	assert.Equal(t, 10, watcher.Get().RateLimit)
+
+	// the overlay of the environment is created after the start
+	writeFile(t, dir, "config.staging.yaml", "rateLimit: 30\n")
+	assert.Eventually(t, func() bool {
+		return watcher.Get().RateLimit == 30
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWatch_NewOverlay(t *testing.T) {
+	t.Run("notify", func(t *testing.T) {
+		testWatchNewOverlay(t)
+	})
+	t.Run("poll", func(t *testing.T) {
+		testWatchNewOverlay(t, config.WithPollInterval(20*time.Millisecond))
+	})
+}
+
+func TestWatch_ConfigMapSymlink(t *testing.T) {
+	// mounted ConfigMaps swap the ..data symlink to a new directory
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "v1"), 0o700))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "v2"), 0o700))
+	writeFile(t, filepath.Join(dir, "v1"), "config.yaml", "rateLimit: 1\n")
+	writeFile(t, filepath.Join(dir, "v2"), "config.yaml", "rateLimit: 2\n")
+	require.NoError(t, os.Symlink("v1", filepath.Join(dir, "..data")))
+	require.NoError(t, os.Symlink(filepath.Join("..data", "config.yaml"), filepath.Join(dir, "config.yaml")))
+
+	watcher, err := config.Watch[watchConfig](config.NewLoader("watch", config.WithSearchPaths(dir)), nil)
+	require.NoError(t, err)
+	defer watcher.Close()
+	assert.Equal(t, 1, watcher.Get().RateLimit)
+
+	require.NoError(t, os.Symlink("v2", filepath.Join(dir, "..data_tmp")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+	assert.Eventually(t, func() bool {
+		return watcher.Get().RateLimit == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "rateLimit: 1\n")
+
+	watcher, err := config.Watch[watchConfig](config.NewLoader("watch", config.WithSearchPaths(dir)), nil)
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	var c changes
+	unsubscribe := watcher.Subscribe(c.add)
+
+	writeFile(t, dir, "config.yaml", "rateLimit: -5\n")
+	assert.Error(t, watcher.Reload())
+	assert.Equal(t, 1, watcher.Get().RateLimit)
+
+	writeFile(t, dir, "config.yaml", "rateLimit: 3\n")
+	unsubscribe()
+	require.NoError(t, watcher.Reload())
+	assert.Equal(t, 3, watcher.Get().RateLimit)
+	assert.Empty(t, c.get())
+}
+
+func TestWatch_InvalidInitialConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.yaml", "rateLimit: -1\n")
+
+	_, err := config.Watch[watchConfig](config.NewLoader("watch", config.WithSearchPaths(dir)), nil)
+	assert.Error(t, err)
+}
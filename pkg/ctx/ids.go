@@ -0,0 +1,51 @@
+package ctx
+
+import (
+	"context"
+)
+
+type IDContextKeyType string
+
+const (
+	RequestIDContextKey IDContextKeyType = "requestId"
+	TenantIDContextKey  IDContextKeyType = "tenantId"
+	TraceIDContextKey   IDContextKeyType = "traceId"
+)
+
+// WithRequestID returns a copy of ctx carrying the ID of the current request.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, RequestIDContextKey, requestID)
+}
+
+// GetRequestID returns the request ID of ctx or an empty string.
+func GetRequestID(ctx context.Context) string {
+	return getString(ctx, RequestIDContextKey)
+}
+
+// WithTenantID returns a copy of ctx carrying the ID of the current tenant.
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, TenantIDContextKey, tenantID)
+}
+
+// GetTenantID returns the tenant ID of ctx or an empty string.
+func GetTenantID(ctx context.Context) string {
+	return getString(ctx, TenantIDContextKey)
+}
+
+// WithTraceID returns a copy of ctx carrying the ID of the current trace.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, TraceIDContextKey, traceID)
+}
+
+// GetTraceID returns the trace ID of ctx or an empty string.
+func GetTraceID(ctx context.Context) string {
+	return getString(ctx, TraceIDContextKey)
+}
+
+func getString(ctx context.Context, key IDContextKeyType) string {
+	if v, ok := ctx.Value(key).(string); ok {
+		return v
+	}
+
+	return ""
+}
@@ -3,12 +3,12 @@ package postgres
 import "fmt"
 
 type Config struct {
-	Host     string            `mapstructure:"host" envconfig:"HOST" default:"127.0.0.1"`
-	Port     int               `mapstructure:"port" envconfig:"PORT" default:"5432"`
-	Database string            `mapstructure:"database" envconfig:"DATABASE" default:"postgres"`
-	User     string            `mapstructure:"user" envconfig:"USER" default:"postgres"`
-	Password string            `mapstructure:"password" envconfig:"PASSWORD" default:"postgres"`
-	Params   map[string]string `mapstructure:"params" envconfig:"PARAMS" default:"sslmode:require"`
+	Host     string            `mapstructure:"host" envconfig:"HOST" default:"127.0.0.1" desc:"database host"`
+	Port     int               `mapstructure:"port" envconfig:"PORT" default:"5432" desc:"database port"`
+	Database string            `mapstructure:"database" envconfig:"DATABASE" default:"postgres" desc:"database name"`
+	User     string            `mapstructure:"user" envconfig:"USER" default:"postgres" desc:"database user"`
+	Password string            `mapstructure:"password" envconfig:"PASSWORD" default:"postgres" secret:"true" desc:"database password"`
+	Params   map[string]string `mapstructure:"params" envconfig:"PARAMS" default:"sslmode:require" desc:"connection parameters, e.g. sslmode:require"`
 }
 
 // DSN assembles the connection string for the given Config.
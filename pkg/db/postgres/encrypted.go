@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/encryption"
+)
+
+// Encrypted is a bytea column value which holds data encrypted with an
+// encryption.Envelope. It implements driver.Valuer and sql.Scanner, so
+// it can be passed directly as a query argument or scan target with pgx.
+// Encryption and decryption take the context of the call site, as the
+// Scanner and Valuer interfaces have none.
+//
+// A nil value is stored as NULL.
+//
+//	col, err := postgres.Encrypt(ctx, envelope, userID, []byte(email))
+//	_, err = pool.Exec(ctx, "INSERT INTO users (id, email) VALUES ($1, $2)", userID, col)
+//
+//	var out postgres.Encrypted
+//	err := pool.QueryRow(ctx, "SELECT email FROM users WHERE id = $1", userID).Scan(&out)
+//	email, err := out.Decrypt(ctx, envelope)
+type Encrypted struct {
+	ciphertext []byte
+}
+
+// Encrypt encrypts the data with the data key of subject. Nil data is
+// stored as NULL.
+func Encrypt(ctx context.Context, envelope *encryption.Envelope, subject string, data []byte) (*Encrypted, error) {
+	if data == nil {
+		return &Encrypted{}, nil
+	}
+
+	ciphertext, err := envelope.Encrypt(ctx, subject, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt column value: %w", err)
+	}
+
+	return &Encrypted{ciphertext: ciphertext}, nil
+}
+
+// Decrypt returns the plaintext of the value, or nil for NULL. The
+// subject is not needed, as it is embedded in the ciphertext.
+func (e *Encrypted) Decrypt(ctx context.Context, envelope *encryption.Envelope) ([]byte, error) {
+	if e.ciphertext == nil {
+		return nil, nil
+	}
+
+	plaintext, err := envelope.Decrypt(ctx, e.ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt column value: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// Value returns the ciphertext for storage in the database.
+func (e *Encrypted) Value() (driver.Value, error) {
+	if e.ciphertext == nil {
+		return nil, nil
+	}
+
+	return e.ciphertext, nil
+}
+
+// Scan reads the ciphertext from the database, see Decrypt.
+func (e *Encrypted) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		e.ciphertext = nil
+		return nil
+	case []byte:
+		// the driver may reuse the buffer
+		e.ciphertext = append([]byte{}, v...)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into encrypted column", src)
+	}
+}
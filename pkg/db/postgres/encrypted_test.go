@@ -0,0 +1,61 @@
+package postgres_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/db/postgres"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/encryption"
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+func TestEncrypted_ValueScan(t *testing.T) {
+	keyring, err := encryption.NewKeyring("master", bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	env := encryption.New(keyring, encryption.WithKeyStore(encryption.NewMemoryKeyStore()))
+	ctx := context.Background()
+
+	in, err := postgres.Encrypt(ctx, env, "user-1", []byte("jane@example.com"))
+	require.NoError(t, err)
+
+	value, err := in.Value()
+	require.NoError(t, err)
+	require.IsType(t, []byte{}, value)
+	assert.NotContains(t, string(value.([]byte)), "jane@example.com")
+
+	var out postgres.Encrypted
+	require.NoError(t, out.Scan(value))
+	data, err := out.Decrypt(ctx, env)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("jane@example.com"), data)
+
+	// NULL values
+	in, err = postgres.Encrypt(ctx, env, "user-1", nil)
+	require.NoError(t, err)
+	value, err = in.Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	require.NoError(t, out.Scan(nil))
+	data, err = out.Decrypt(ctx, env)
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	assert.Error(t, out.Scan("text"))
+
+	// shredded subjects cannot be decrypted anymore
+	in, err = postgres.Encrypt(ctx, env, "user-1", []byte("jane@example.com"))
+	require.NoError(t, err)
+	value, err = in.Value()
+	require.NoError(t, err)
+	require.NoError(t, env.Shred(ctx, "user-1"))
+	require.NoError(t, out.Scan(value))
+	_, err = out.Decrypt(ctx, env)
+	assert.True(t, errors.Is(errors.Gone, err))
+	e, ok := errors.As(err)
+	require.True(t, ok)
+	assert.Equal(t, encryption.CodeKeyShredded, e.Code)
+}
@@ -3,12 +3,12 @@ package redis
 import "fmt"
 
 type Config struct {
-	Hosts     string `envconfig:"HOSTS" default:"127.0.0.1" required:"true"`
-	Port      int    `envconfig:"PORT" default:"6379" required:"true"`
-	Username  string `envconfig:"USERNAME"`
-	Password  string `envconfig:"PASSWORD"`
-	Database  int    `envconfig:"DATABASE" default:"0"`
-	IsCluster bool   `envconfig:"DATABASE" default:"false"`
+	Hosts     string `mapstructure:"hosts" envconfig:"HOSTS" default:"127.0.0.1" required:"true" desc:"redis host, or hosts separated by ; for clusters"`
+	Port      int    `mapstructure:"port" envconfig:"PORT" default:"6379" required:"true" desc:"redis port"`
+	Username  string `mapstructure:"username" envconfig:"USERNAME" desc:"redis user"`
+	Password  string `mapstructure:"password" envconfig:"PASSWORD" secret:"true" desc:"redis password"`
+	Database  int    `mapstructure:"database" envconfig:"DATABASE" default:"0" desc:"redis database number"`
+	IsCluster bool   `mapstructure:"isCluster" envconfig:"IS_CLUSTER" default:"false" desc:"connects to a redis cluster"`
 }
 
 func (c Config) DSN() string {
@@ -0,0 +1,22 @@
+package encryption
+
+import "context"
+
+type SubjectContextKeyType string
+
+const SubjectContextKey SubjectContextKeyType = "encryptionSubject"
+
+// WithSubject returns a context carrying the subject whose data key
+// should be used by clients encrypting values transparently.
+func WithSubject(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, SubjectContextKey, subject)
+}
+
+// GetSubject returns the subject stored in the context, if any.
+func GetSubject(ctx context.Context) string {
+	if subject, ok := ctx.Value(SubjectContextKey).(string); ok {
+		return subject
+	}
+
+	return ""
+}
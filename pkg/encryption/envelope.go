@@ -0,0 +1,388 @@
+// Package encryption implements client-side envelope encryption
+// of personal data with AES-GCM.
+//
+// Every value is sealed with a fresh random data encryption key (DEK).
+// The DEK is wrapped either with a master key from a Keyring, or with
+// the data key of a subject (e.g. the person the data belongs to).
+// Subject data keys are persisted in a KeyStore, wrapped with a master
+// key. Deleting the data key of a subject with Envelope.Shred makes
+// all values encrypted for that subject unreadable (crypto-shredding).
+//
+// The ID of the key wrapping the DEK is embedded in the ciphertext,
+// so values stay readable after master keys have been rotated and
+// can be re-encrypted with the new primary key at any time.
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+const (
+	formatVersion byte = 1
+
+	keyTypeMaster  byte = 1
+	keyTypeSubject byte = 2
+
+	maxKeyIDLen = 255
+	dataKeySize = 32
+)
+
+// CodeKeyShredded is the code of errors returned by Envelope.Decrypt for
+// values of a subject whose data key has been shredded. Its Kind Gone
+// tells them apart from missing values, e.g. cache misses.
+var CodeKeyShredded = errors.RegisterCode(errors.CodeInfo{
+	Code:    "KEY_SHREDDED",
+	Kind:    errors.Gone,
+	Message: "the data of the subject has been deleted",
+})
+
+// KeyRef identifies the key embedded in a ciphertext.
+type KeyRef struct {
+	// ID is the master key ID or the subject.
+	ID string
+
+	// Subject reports whether ID refers to the data key of a subject.
+	Subject bool
+}
+
+// Envelope encrypts and decrypts values with keys from a Keyring
+// and, for subject bound values, a KeyStore.
+type Envelope struct {
+	keyring *Keyring
+	store   KeyStore
+}
+
+func New(keyring *Keyring, opts ...Option) *Envelope {
+	e := &Envelope{keyring: keyring}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Encrypt seals plaintext with a fresh data encryption key. If subject
+// is not empty, the key is wrapped with the data key of the subject,
+// which is created on first use. Otherwise it is wrapped with the
+// primary master key.
+func (e *Envelope) Encrypt(ctx context.Context, subject string, plaintext []byte) ([]byte, error) {
+	ref := KeyRef{ID: subject, Subject: subject != ""}
+
+	var kek []byte
+	var err error
+	if ref.Subject {
+		kek, err = e.subjectKey(ctx, subject)
+	} else {
+		ref.ID, kek = e.keyring.primaryKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dek := make([]byte, dataKeySize)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, errors.New(errors.Internal, "failed to generate data key", err)
+	}
+
+	wrapped, err := seal(kek, dek, []byte(ref.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := encodeHeader(ref, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := seal(dek, plaintext, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header, body...), nil
+}
+
+// Decrypt opens a ciphertext created by Encrypt. It returns an error
+// with code CodeKeyShredded if the data key of the subject has been
+// shredded, and an error of Kind errors.NotFound if the master key does
+// not exist anymore. A subject key whose master key has been removed
+// without re-wrapping it first is an errors.Internal error.
+func (e *Envelope) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	ref, wrapped, n, err := decodeHeader(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	var kek []byte
+	if ref.Subject {
+		kek, _, err = e.loadSubjectKey(ctx, ref.ID)
+		if errors.Is(errors.NotFound, err) {
+			return nil, errors.New(CodeKeyShredded, errors.InternalMessage("subject key not found: "+ref.ID))
+		}
+	} else {
+		kek, err = e.keyring.key(ref.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dek, err := open(kek, wrapped, []byte(ref.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	return open(dek, ciphertext[n:], ciphertext[:n])
+}
+
+// Reencrypt decrypts the ciphertext and encrypts it again with the
+// current primary master key. For subject bound values the data key
+// of the subject is re-wrapped with the primary master key as well.
+func (e *Envelope) Reencrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	ref, err := ParseKeyRef(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := e.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ref.Subject {
+		return e.Encrypt(ctx, "", plaintext)
+	}
+
+	if err := e.RewrapSubjectKey(ctx, ref.ID); err != nil {
+		return nil, err
+	}
+
+	return e.Encrypt(ctx, ref.ID, plaintext)
+}
+
+// RewrapSubjectKey wraps the data key of the subject with the current
+// primary master key, if it is wrapped with an older one.
+func (e *Envelope) RewrapSubjectKey(ctx context.Context, subject string) error {
+	key, masterID, err := e.loadSubjectKey(ctx, subject)
+	if err != nil {
+		return err
+	}
+
+	if masterID == e.keyring.Primary() {
+		return nil
+	}
+
+	record, err := e.wrapSubjectKey(subject, key)
+	if err != nil {
+		return err
+	}
+
+	return e.store.Put(ctx, subject, record)
+}
+
+// Shred deletes the data key of the subject. All values encrypted
+// for the subject become permanently unreadable.
+func (e *Envelope) Shred(ctx context.Context, subject string) error {
+	if e.store == nil {
+		return errors.New(errors.Internal, "no key store configured for subject keys")
+	}
+
+	return e.store.Delete(ctx, subject)
+}
+
+// ParseKeyRef returns the reference to the wrapping key
+// embedded in the ciphertext.
+func ParseKeyRef(ciphertext []byte) (KeyRef, error) {
+	ref, _, _, err := decodeHeader(ciphertext)
+	return ref, err
+}
+
+// subjectKey returns the data key of the subject and creates it
+// if it does not exist yet.
+func (e *Envelope) subjectKey(ctx context.Context, subject string) ([]byte, error) {
+	key, _, err := e.loadSubjectKey(ctx, subject)
+	if err == nil || !errors.Is(errors.NotFound, err) {
+		return key, err
+	}
+
+	key = make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.New(errors.Internal, "failed to generate subject key", err)
+	}
+
+	record, err := e.wrapSubjectKey(subject, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.store.Create(ctx, subject, record); err != nil {
+		if !errors.Is(errors.Exist, err) {
+			return nil, err
+		}
+
+		// another writer created the key concurrently
+		key, _, err = e.loadSubjectKey(ctx, subject)
+		return key, err
+	}
+
+	return key, nil
+}
+
+func (e *Envelope) loadSubjectKey(ctx context.Context, subject string) (key []byte, masterID string, err error) {
+	if e.store == nil {
+		return nil, "", errors.New(errors.Internal, "no key store configured for subject keys")
+	}
+	if subject == "" || len(subject) > maxKeyIDLen {
+		return nil, "", errors.New(errors.BadRequest, "invalid subject")
+	}
+
+	record, err := e.store.Get(ctx, subject)
+	if err != nil {
+		if errors.Is(errors.NotFound, err) {
+			return nil, "", errors.New(errors.NotFound, "subject key not found", err)
+		}
+		return nil, "", err
+	}
+
+	if len(record) < 1 || len(record) < 1+int(record[0]) {
+		return nil, "", errors.New(errors.Internal, "malformed subject key record")
+	}
+	masterID = string(record[1 : 1+int(record[0])])
+
+	// the subject key exists, so a missing master key is not a miss
+	master, err := e.keyring.key(masterID)
+	if err != nil {
+		return nil, "", errors.New(errors.Internal, "master key of subject key not found", err)
+	}
+
+	key, err = open(master, record[1+int(record[0]):], []byte(subject))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, masterID, nil
+}
+
+// wrapSubjectKey encodes the subject key wrapped with the primary
+// master key as: len(masterID) | masterID | nonce | sealed key.
+func (e *Envelope) wrapSubjectKey(subject string, key []byte) ([]byte, error) {
+	if e.store == nil {
+		return nil, errors.New(errors.Internal, "no key store configured for subject keys")
+	}
+
+	masterID, master := e.keyring.primaryKey()
+	wrapped, err := seal(master, key, []byte(subject))
+	if err != nil {
+		return nil, err
+	}
+
+	record := make([]byte, 0, 1+len(masterID)+len(wrapped))
+	record = append(record, byte(len(masterID)))
+	record = append(record, masterID...)
+
+	return append(record, wrapped...), nil
+}
+
+// encodeHeader encodes the ciphertext header as:
+// version | key type | len(key ID) | key ID | len(wrapped DEK) (uint16) | wrapped DEK.
+// The header is authenticated as additional data of the sealed body.
+func encodeHeader(ref KeyRef, wrapped []byte) ([]byte, error) {
+	if ref.ID == "" || len(ref.ID) > maxKeyIDLen {
+		return nil, errors.New(errors.BadRequest, "invalid key id")
+	}
+
+	keyType := keyTypeMaster
+	if ref.Subject {
+		keyType = keyTypeSubject
+	}
+
+	header := make([]byte, 0, 5+len(ref.ID)+len(wrapped))
+	header = append(header, formatVersion, keyType, byte(len(ref.ID)))
+	header = append(header, ref.ID...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrapped)))
+
+	return append(header, wrapped...), nil
+}
+
+func decodeHeader(ciphertext []byte) (ref KeyRef, wrapped []byte, n int, err error) {
+	malformed := errors.New(errors.BadRequest, "malformed ciphertext")
+
+	if len(ciphertext) < 3 || ciphertext[0] != formatVersion {
+		return ref, nil, 0, malformed
+	}
+
+	switch ciphertext[1] {
+	case keyTypeMaster:
+	case keyTypeSubject:
+		ref.Subject = true
+	default:
+		return ref, nil, 0, malformed
+	}
+
+	n = 3 + int(ciphertext[2])
+	if len(ciphertext) < n+2 {
+		return ref, nil, 0, malformed
+	}
+	ref.ID = string(ciphertext[3:n])
+
+	wrappedLen := int(binary.BigEndian.Uint16(ciphertext[n:]))
+	n += 2
+	if len(ciphertext) < n+wrappedLen {
+		return ref, nil, 0, malformed
+	}
+
+	return ref, ciphertext[n : n+wrappedLen], n + wrappedLen, nil
+}
+
+// seal encrypts plaintext with AES-GCM and returns nonce | ciphertext.
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.New(errors.Internal, "failed to generate nonce", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key, data, additionalData []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New(errors.BadRequest, "malformed ciphertext")
+	}
+
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, errors.New(errors.BadRequest, "failed to decrypt", err)
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.New(errors.Internal, "invalid encryption key", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.New(errors.Internal, "failed to create cipher", err)
+	}
+
+	return aead, nil
+}
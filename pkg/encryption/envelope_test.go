@@ -0,0 +1,206 @@
+package encryption_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/encryption"
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+func newKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func newEnvelope(t *testing.T) (*encryption.Envelope, *encryption.Keyring) {
+	keyring, err := encryption.NewKeyring("master-1", newKey(1))
+	require.NoError(t, err)
+
+	return encryption.New(keyring, encryption.WithKeyStore(encryption.NewMemoryKeyStore())), keyring
+}
+
+func TestNewKeyring(t *testing.T) {
+	_, err := encryption.NewKeyring("", newKey(1))
+	assert.True(t, errors.Is(errors.BadRequest, err))
+
+	_, err = encryption.NewKeyring("master", []byte("short"))
+	assert.True(t, errors.Is(errors.BadRequest, err))
+
+	keyring, err := encryption.NewKeyring("master", newKey(1))
+	require.NoError(t, err)
+	assert.Equal(t, "master", keyring.Primary())
+
+	err = keyring.Add("master", newKey(2))
+	assert.True(t, errors.Is(errors.Exist, err))
+
+	err = keyring.Remove("master")
+	assert.True(t, errors.Is(errors.BadRequest, err))
+}
+
+func TestEnvelope_EncryptDecrypt(t *testing.T) {
+	env, _ := newEnvelope(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		subject string
+	}{
+		{name: "master key", subject: ""},
+		{name: "subject key", subject: "user-1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ciphertext, err := env.Encrypt(ctx, test.subject, []byte("jane@example.com"))
+			require.NoError(t, err)
+			assert.NotContains(t, string(ciphertext), "jane@example.com")
+
+			ref, err := encryption.ParseKeyRef(ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, test.subject != "", ref.Subject)
+			if test.subject != "" {
+				assert.Equal(t, test.subject, ref.ID)
+			} else {
+				assert.Equal(t, "master-1", ref.ID)
+			}
+
+			plaintext, err := env.Decrypt(ctx, ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("jane@example.com"), plaintext)
+		})
+	}
+}
+
+func TestEnvelope_DecryptTampered(t *testing.T) {
+	env, _ := newEnvelope(t)
+
+	ciphertext, err := env.Encrypt(context.Background(), "user-1", []byte("data"))
+	require.NoError(t, err)
+
+	ciphertext[len(ciphertext)-1] ^= 0xff
+	_, err = env.Decrypt(context.Background(), ciphertext)
+	assert.True(t, errors.Is(errors.BadRequest, err))
+
+	_, err = env.Decrypt(context.Background(), []byte("plaintext"))
+	assert.True(t, errors.Is(errors.BadRequest, err))
+}
+
+func TestEnvelope_Rotation(t *testing.T) {
+	env, keyring := newEnvelope(t)
+	ctx := context.Background()
+
+	old, err := env.Encrypt(ctx, "", []byte("data"))
+	require.NoError(t, err)
+	oldSubject, err := env.Encrypt(ctx, "user-1", []byte("subject data"))
+	require.NoError(t, err)
+
+	require.NoError(t, keyring.Rotate("master-2", newKey(2)))
+
+	// old values stay readable with the previous master key
+	plaintext, err := env.Decrypt(ctx, old)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), plaintext)
+
+	reencrypted, err := env.Reencrypt(ctx, old)
+	require.NoError(t, err)
+	ref, err := encryption.ParseKeyRef(reencrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "master-2", ref.ID)
+
+	reencryptedSubject, err := env.Reencrypt(ctx, oldSubject)
+	require.NoError(t, err)
+
+	// after re-encryption the old master key can be removed
+	require.NoError(t, keyring.Remove("master-1"))
+
+	_, err = env.Decrypt(ctx, old)
+	assert.True(t, errors.Is(errors.NotFound, err))
+
+	plaintext, err = env.Decrypt(ctx, reencrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), plaintext)
+
+	plaintext, err = env.Decrypt(ctx, reencryptedSubject)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("subject data"), plaintext)
+
+	// the subject key was re-wrapped, so old subject values are readable too
+	plaintext, err = env.Decrypt(ctx, oldSubject)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("subject data"), plaintext)
+}
+
+func TestEnvelope_RemovedMasterKeyOfSubject(t *testing.T) {
+	env, keyring := newEnvelope(t)
+	ctx := context.Background()
+
+	ciphertext, err := env.Encrypt(ctx, "user-1", []byte("subject data"))
+	require.NoError(t, err)
+
+	// the subject key is still wrapped with master-1
+	require.NoError(t, keyring.Rotate("master-2", newKey(2)))
+	require.NoError(t, keyring.Remove("master-1"))
+
+	_, err = env.Decrypt(ctx, ciphertext)
+	assert.True(t, errors.Is(errors.Internal, err))
+	assert.False(t, errors.Is(errors.Gone, err))
+	e, ok := errors.As(err)
+	require.True(t, ok)
+	assert.NotEqual(t, encryption.CodeKeyShredded, e.Code)
+
+	// no new subject key replaces the intact one
+	_, err = env.Encrypt(ctx, "user-1", []byte("new data"))
+	assert.True(t, errors.Is(errors.Internal, err))
+}
+
+func TestEnvelope_Shred(t *testing.T) {
+	env, _ := newEnvelope(t)
+	ctx := context.Background()
+
+	user1, err := env.Encrypt(ctx, "user-1", []byte("user 1 data"))
+	require.NoError(t, err)
+	user2, err := env.Encrypt(ctx, "user-2", []byte("user 2 data"))
+	require.NoError(t, err)
+
+	require.NoError(t, env.Shred(ctx, "user-1"))
+
+	_, err = env.Decrypt(ctx, user1)
+	assert.True(t, errors.Is(errors.Gone, err))
+	assert.False(t, errors.Is(errors.NotFound, err))
+	e, ok := errors.As(err)
+	require.True(t, ok)
+	assert.Equal(t, encryption.CodeKeyShredded, e.Code)
+
+	plaintext, err := env.Decrypt(ctx, user2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("user 2 data"), plaintext)
+
+	// a new key is created for the subject, old data stays unreadable
+	_, err = env.Encrypt(ctx, "user-1", []byte("new data"))
+	require.NoError(t, err)
+	_, err = env.Decrypt(ctx, user1)
+	assert.Error(t, err)
+}
+
+func TestEnvelope_WithoutKeyStore(t *testing.T) {
+	keyring, err := encryption.NewKeyring("master", newKey(1))
+	require.NoError(t, err)
+	env := encryption.New(keyring)
+
+	_, err = env.Encrypt(context.Background(), "user-1", []byte("data"))
+	assert.True(t, errors.Is(errors.Internal, err))
+
+	_, err = env.Encrypt(context.Background(), "", []byte("data"))
+	assert.NoError(t, err)
+}
+
+func TestSubjectContext(t *testing.T) {
+	assert.Equal(t, "", encryption.GetSubject(context.Background()))
+
+	ctx := encryption.WithSubject(context.Background(), "user-1")
+	assert.Equal(t, "user-1", encryption.GetSubject(ctx))
+}
@@ -0,0 +1,119 @@
+package encryption
+
+import (
+	"sync"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+// Keyring holds the master keys used to wrap data keys.
+//
+// Exactly one key is primary and used for new encryptions. Older
+// keys stay available for decryption until they are removed, which
+// allows rotating master keys without losing access to existing data.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string][]byte
+	primary string
+}
+
+// NewKeyring creates a keyring with the given primary master key.
+// The key must be 16, 24 or 32 bytes long to select AES-128,
+// AES-192 or AES-256.
+func NewKeyring(id string, key []byte) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string][]byte)}
+	if err := k.Rotate(id, key); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// Add adds a master key which can be used for decryption.
+func (k *Keyring) Add(id string, key []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.add(id, key)
+}
+
+// Rotate adds a master key and makes it the primary key.
+func (k *Keyring) Rotate(id string, key []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.add(id, key); err != nil {
+		return err
+	}
+	k.primary = id
+
+	return nil
+}
+
+// add adds a master key, k.mu must be held.
+func (k *Keyring) add(id string, key []byte) error {
+	if id == "" || len(id) > maxKeyIDLen {
+		return errors.New(errors.BadRequest, "invalid master key id")
+	}
+	if !validKeySize(key) {
+		return errors.New(errors.BadRequest, "invalid master key size")
+	}
+
+	if _, ok := k.keys[id]; ok {
+		return errors.New(errors.Exist, "master key already exists: "+id)
+	}
+	k.keys[id] = append([]byte(nil), key...)
+
+	return nil
+}
+
+// Remove deletes a master key. The primary key cannot be removed.
+// Data keys still wrapped with the removed key become unreadable,
+// so Envelope.Reencrypt should be used before removing old keys.
+func (k *Keyring) Remove(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if id == k.primary {
+		return errors.New(errors.BadRequest, "cannot remove primary master key")
+	}
+	delete(k.keys, id)
+
+	return nil
+}
+
+// Primary returns the ID of the primary master key.
+func (k *Keyring) Primary() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.primary
+}
+
+func (k *Keyring) primaryKey() (string, []byte) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.primary, k.keys[k.primary]
+}
+
+func (k *Keyring) key(id string) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, errors.New(errors.NotFound, "master key not found: "+id)
+	}
+
+	return key, nil
+}
+
+func validKeySize(key []byte) bool {
+	switch len(key) {
+	case 16, 24, 32:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,82 @@
+package encryption
+
+import (
+	"context"
+	"sync"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+// KeyStore persists the wrapped data keys of subjects.
+//
+// Values are opaque to the store. They are encrypted with a master
+// key from the Keyring, so the store itself does not need to be
+// trusted with plaintext keys.
+type KeyStore interface {
+	// Get returns the value stored for id or an error of
+	// Kind errors.NotFound.
+	Get(ctx context.Context, id string) ([]byte, error)
+
+	// Create stores the value for id if it does not exist yet,
+	// otherwise it returns an error of Kind errors.Exist.
+	Create(ctx context.Context, id string, value []byte) error
+
+	// Put stores the value for id, replacing any existing one.
+	Put(ctx context.Context, id string, value []byte) error
+
+	// Delete removes the value stored for id. Deleting a missing
+	// id is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryKeyStore is a KeyStore which keeps keys in memory.
+type MemoryKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string][]byte
+}
+
+func NewMemoryKeyStore() *MemoryKeyStore {
+	return &MemoryKeyStore{keys: make(map[string][]byte)}
+}
+
+func (s *MemoryKeyStore) Get(_ context.Context, id string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.keys[id]
+	if !ok {
+		return nil, errors.New(errors.NotFound, "key not found")
+	}
+
+	return append([]byte(nil), v...), nil
+}
+
+func (s *MemoryKeyStore) Create(_ context.Context, id string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[id]; ok {
+		return errors.New(errors.Exist, "key already exists")
+	}
+	s.keys[id] = append([]byte(nil), value...)
+
+	return nil
+}
+
+func (s *MemoryKeyStore) Put(_ context.Context, id string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[id] = append([]byte(nil), value...)
+
+	return nil
+}
+
+func (s *MemoryKeyStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, id)
+
+	return nil
+}
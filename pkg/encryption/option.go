@@ -0,0 +1,11 @@
+package encryption
+
+type Option func(*Envelope)
+
+// WithKeyStore sets the store for the data keys of subjects.
+// Without a key store only values without subject can be encrypted.
+func WithKeyStore(store KeyStore) Option {
+	return func(e *Envelope) {
+		e.store = store
+	}
+}
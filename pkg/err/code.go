@@ -0,0 +1,191 @@
+package err
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Code is a stable, machine readable identifier of a specific failure,
+// e.g. POLICY_NOT_FOUND. Unlike the Kind, which is coarse, and the
+// Message, which is free text, clients can reliably branch on codes.
+//
+// Codes are declared once per service with RegisterCode:
+//
+//	var CodePolicyNotFound = errors.RegisterCode(errors.CodeInfo{
+//		Code:    "POLICY_NOT_FOUND",
+//		Kind:    errors.NotFound,
+//		Message: "policy not found",
+//		DocsURL: "https://docs.example.com/errors#POLICY_NOT_FOUND",
+//	})
+//
+// and passed to New like any other argument:
+//
+//	return errors.New(CodePolicyNotFound, err)
+type Code string
+
+// CodeInfo describes a registered Code.
+type CodeInfo struct {
+	// Code is the registered code.
+	Code Code `json:"code"`
+
+	// Kind is the Kind of errors with this code, unless another
+	// Kind is given to New.
+	Kind Kind `json:"kind"`
+
+	// Message is the default message of errors with this code.
+	Message string `json:"message,omitempty"`
+
+	// DocsURL points to the documentation of the code. It is used
+	// as the type of Problem Details.
+	DocsURL string `json:"docsUrl,omitempty"`
+}
+
+var (
+	codesMu sync.RWMutex
+	codes   = map[Code]CodeInfo{}
+)
+
+// RegisterCode adds a code to the registry and returns it.
+// It panics if the code is empty or registered already, so it
+// is meant to be called on package initialization.
+func RegisterCode(info CodeInfo) Code {
+	if info.Code == "" {
+		panic("call to errors.RegisterCode with empty code")
+	}
+
+	codesMu.Lock()
+	defer codesMu.Unlock()
+
+	if _, ok := codes[info.Code]; ok {
+		panic(fmt.Sprintf("error code %s registered twice", info.Code))
+	}
+	codes[info.Code] = info
+
+	return info.Code
+}
+
+// LookupCode returns the registered information of a code.
+func LookupCode(code Code) (CodeInfo, bool) {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+
+	info, ok := codes[code]
+	return info, ok
+}
+
+// Catalog returns all registered codes sorted by code.
+func Catalog() []CodeInfo {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+
+	catalog := make([]CodeInfo, 0, len(codes))
+	for _, info := range codes {
+		catalog = append(catalog, info)
+	}
+	sort.Slice(catalog, func(i, j int) bool {
+		return catalog[i].Code < catalog[j].Code
+	})
+
+	return catalog
+}
+
+// CodeOf returns the Code of the first *Error in the chain of err
+// which has a code. Both single and multiple wrapping is followed.
+func CodeOf(err error) Code {
+	switch e := err.(type) {
+	case nil:
+		return ""
+	case *Error:
+		if e.Code != "" {
+			return e.Code
+		}
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return CodeOf(e.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if code := CodeOf(inner); code != "" {
+				return code
+			}
+		}
+	}
+
+	return ""
+}
+
+// catalogEntry is the exported representation of a registered code.
+type catalogEntry struct {
+	Code    Code   `json:"code"`
+	Kind    string `json:"kind"`
+	Status  int    `json:"status"`
+	Message string `json:"message,omitempty"`
+	DocsURL string `json:"docsUrl,omitempty"`
+}
+
+func catalogEntries() []catalogEntry {
+	catalog := Catalog()
+
+	entries := make([]catalogEntry, 0, len(catalog))
+	for _, info := range catalog {
+		entries = append(entries, catalogEntry{
+			Code:    info.Code,
+			Kind:    info.Kind.String(),
+			Status:  (&Error{Kind: info.Kind}).StatusCode(),
+			Message: info.Message,
+			DocsURL: info.DocsURL,
+		})
+	}
+
+	return entries
+}
+
+// WriteCatalogJSON writes all registered codes as JSON array,
+// including the name of their Kind and their HTTP status code.
+func WriteCatalogJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(catalogEntries())
+}
+
+// WriteCatalogMarkdown writes all registered codes as Markdown table.
+func WriteCatalogMarkdown(w io.Writer) error {
+	b := new(strings.Builder)
+	b.WriteString("| Code | Kind | Status | Message |\n")
+	b.WriteString("|------|------|--------|---------|\n")
+
+	for _, entry := range catalogEntries() {
+		code := string(entry.Code)
+		if entry.DocsURL != "" {
+			code = fmt.Sprintf("[%s](%s)", code, entry.DocsURL)
+		}
+		fmt.Fprintf(b, "| %s | %s | %d | %s |\n", code, entry.Kind, entry.Status, markdownEscape(entry.Message))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// MergeCatalog adds all registered codes to the JSON encoded OpenAPI
+// (Swagger) document as x-error-codes extension.
+func MergeCatalog(doc []byte) ([]byte, error) {
+	var spec map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &spec); err != nil {
+		return nil, fmt.Errorf("failed to decode swagger document: %w", err)
+	}
+
+	catalog, err := json.Marshal(catalogEntries())
+	if err != nil {
+		return nil, err
+	}
+	spec["x-error-codes"] = catalog
+
+	return json.Marshal(spec)
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
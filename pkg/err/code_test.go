@@ -0,0 +1,118 @@
+package err_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+var (
+	codePolicyNotFound = errors.RegisterCode(errors.CodeInfo{
+		Code:    "POLICY_NOT_FOUND",
+		Kind:    errors.NotFound,
+		Message: "policy not found",
+		DocsURL: "https://docs.example.com/errors#POLICY_NOT_FOUND",
+	})
+	codeQuotaExceeded = errors.RegisterCode(errors.CodeInfo{
+		Code:    "QUOTA_EXCEEDED",
+		Kind:    errors.TooManyRequests,
+		Message: "quota | limit exceeded",
+	})
+)
+
+func TestRegisterCode(t *testing.T) {
+	info, ok := errors.LookupCode(codePolicyNotFound)
+	require.True(t, ok)
+	assert.Equal(t, errors.NotFound, info.Kind)
+
+	_, ok = errors.LookupCode("UNKNOWN_CODE")
+	assert.False(t, ok)
+
+	assert.Panics(t, func() {
+		errors.RegisterCode(errors.CodeInfo{Code: codePolicyNotFound})
+	})
+	assert.Panics(t, func() {
+		errors.RegisterCode(errors.CodeInfo{})
+	})
+}
+
+func TestNew_Code(t *testing.T) {
+	e := errors.New(codePolicyNotFound, fmt.Errorf("no rows")).(*errors.Error)
+	assert.Equal(t, codePolicyNotFound, e.Code)
+	assert.Equal(t, errors.NotFound, e.Kind)
+	assert.Equal(t, "policy not found", e.Message)
+
+	// explicit kind and message win
+	e = errors.New(codePolicyNotFound, errors.Internal, "policy store unavailable").(*errors.Error)
+	assert.Equal(t, errors.Internal, e.Kind)
+	assert.Equal(t, "policy store unavailable", e.Message)
+
+	// the code is promoted from the underlying error
+	wrapped := errors.New("failed to evaluate policy", e)
+	assert.Equal(t, codePolicyNotFound, errors.CodeOf(fmt.Errorf("wrapped: %w", wrapped)))
+	assert.Equal(t, errors.Code(""), errors.CodeOf(fmt.Errorf("plain")))
+
+	// unregistered codes are kept as is
+	e = errors.New(errors.Code("UNREGISTERED"), "message").(*errors.Error)
+	assert.Equal(t, errors.Code("UNREGISTERED"), e.Code)
+	assert.Equal(t, errors.Unknown, e.Kind)
+}
+
+func TestCode_JSON(t *testing.T) {
+	e := errors.New(codePolicyNotFound)
+
+	rr := httptest.NewRecorder()
+	errors.JSON(rr, e)
+	parsed, err := errors.Parse(rr.Header().Get("Content-Type"), rr.Body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, codePolicyNotFound, parsed.Code)
+
+	rr = httptest.NewRecorder()
+	errors.WriteProblem(rr, httptest.NewRequest(http.MethodGet, "/", nil), e)
+
+	var p errors.Problem
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p))
+	assert.Equal(t, "https://docs.example.com/errors#POLICY_NOT_FOUND", p.Type)
+	assert.Equal(t, codePolicyNotFound, p.ToError().Code)
+}
+
+func TestWriteCatalog(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, errors.WriteCatalogJSON(buf))
+
+	var catalog []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &catalog))
+	assert.Contains(t, catalog, map[string]interface{}{
+		"code":    "POLICY_NOT_FOUND",
+		"kind":    "not found",
+		"status":  float64(http.StatusNotFound),
+		"message": "policy not found",
+		"docsUrl": "https://docs.example.com/errors#POLICY_NOT_FOUND",
+	})
+
+	buf.Reset()
+	require.NoError(t, errors.WriteCatalogMarkdown(buf))
+	assert.Contains(t, buf.String(), "| [POLICY_NOT_FOUND](https://docs.example.com/errors#POLICY_NOT_FOUND) | not found | 404 | policy not found |")
+	assert.Contains(t, buf.String(), `| QUOTA_EXCEEDED | too many requests | 429 | quota \| limit exceeded |`)
+}
+
+func TestMergeCatalog(t *testing.T) {
+	merged, err := errors.MergeCatalog([]byte(`{"swagger":"2.0","paths":{}}`))
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(merged, &doc))
+	assert.Equal(t, "2.0", doc["swagger"])
+	assert.NotEmpty(t, doc["x-error-codes"])
+
+	_, err = errors.MergeCatalog([]byte("not json"))
+	assert.Error(t, err)
+}
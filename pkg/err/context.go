@@ -0,0 +1,67 @@
+package err
+
+import (
+	"context"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+)
+
+// Metadata keys of the identifiers recorded by NewWithContext.
+const (
+	MetadataRequestID = "requestId"
+	MetadataTenantID  = "tenantId"
+	MetadataTraceID   = "traceId"
+)
+
+// idSuffixLength is the length of the random suffix of error IDs
+// derived from a request ID.
+const idSuffixLength = 6
+
+// NewWithContext builds an error like New and records the request, tenant
+// and trace IDs of the context (see ctx.WithRequestID, ctx.WithTenantID
+// and ctx.WithTraceID) in its Metadata, so the error ID is linked to them
+// in responses and logs. Metadata given explicitly is not overwritten.
+//
+// With a request ID in the context, the error ID is derived from it as
+// <request ID>-<random suffix>, so all errors of a request are found by
+// the request ID. Errors built from an *Error keep its ID. A nil context
+// has no IDs.
+func NewWithContext(c context.Context, args ...interface{}) error {
+	e := newError(args)
+	if c == nil {
+		return e
+	}
+
+	if requestID := ctx.GetRequestID(c); requestID != "" && !hasError(args) {
+		e.ID = requestID + "-" + ShortID()[:idSuffixLength]
+	}
+
+	ids := map[string]string{
+		MetadataRequestID: ctx.GetRequestID(c),
+		MetadataTenantID:  ctx.GetTenantID(c),
+		MetadataTraceID:   ctx.GetTraceID(c),
+	}
+	for key, id := range ids {
+		if id == "" {
+			continue
+		}
+		if _, ok := e.Metadata[key]; ok {
+			continue
+		}
+		e.addMetadata(Metadata{key: id})
+	}
+
+	return e
+}
+
+// hasError reports whether the args contain an *Error, whose ID is
+// inherited by New.
+func hasError(args []interface{}) bool {
+	for _, arg := range args {
+		if _, ok := arg.(*Error); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,55 @@
+package err_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+func TestNewWithContext(t *testing.T) {
+	c := ctx.WithRequestID(context.Background(), "req-123")
+	c = ctx.WithTenantID(c, "tenant-1")
+	c = ctx.WithTraceID(c, "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	e := errors.NewWithContext(c, errors.NotFound, "policy not found").(*errors.Error)
+	assert.Equal(t, errors.NotFound, e.Kind)
+	assert.Equal(t, errors.Metadata{
+		errors.MetadataRequestID: "req-123",
+		errors.MetadataTenantID:  "tenant-1",
+		errors.MetadataTraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+	}, e.Metadata)
+
+	// explicit metadata is kept
+	e = errors.NewWithContext(c, errors.Metadata{errors.MetadataTenantID: "other"}).(*errors.Error)
+	assert.Equal(t, "other", e.Metadata[errors.MetadataTenantID])
+	assert.Equal(t, "req-123", e.Metadata[errors.MetadataRequestID])
+
+	// the error ID is derived from the request ID
+	assert.Regexp(t, `^req-123-[0-9A-Za-z]{6}$`, e.ID)
+
+	// errors built from an *Error keep its ID
+	inner := errors.New(errors.NotFound).(*errors.Error)
+	e = errors.NewWithContext(c, inner).(*errors.Error)
+	assert.Equal(t, inner.ID, e.ID)
+
+	// nothing is recorded without IDs in the context
+	e = errors.NewWithContext(context.Background(), "plain").(*errors.Error)
+	assert.Empty(t, e.Metadata)
+
+	//nolint:staticcheck // a nil context has no IDs
+	e = errors.NewWithContext(nil, "plain").(*errors.Error)
+	assert.Empty(t, e.Metadata)
+	assert.NotEmpty(t, e.ID)
+}
+
+func TestNewWithContext_Stack(t *testing.T) {
+	defer errors.SetStackCapture(errors.StackCapture())
+	errors.SetStackCapture(true)
+
+	e := errors.NewWithContext(context.Background(), "with stack").(*errors.Error)
+	assert.Regexp(t, `^github.com/eclipse-xfsc/microservice-core-go/pkg/err_test.TestNewWithContext_Stack\n`, e.StackTrace())
+}
@@ -0,0 +1,55 @@
+package err
+
+// FieldViolation describes a single invalid field of a request,
+// so clients can point users to the field without parsing messages.
+type FieldViolation struct {
+	// Path of the invalid field, e.g. "address.zipCode" or "items[2].id".
+	Path string `json:"path"`
+
+	// Code is a machine readable reason, e.g. "required" or "max".
+	Code string `json:"code,omitempty"`
+
+	// Message is a human readable description of the violation.
+	Message string `json:"message,omitempty"`
+}
+
+// Metadata holds arbitrary structured information about an error.
+// When passed to New, it is merged into the metadata of the error.
+type Metadata map[string]interface{}
+
+// InternalMessage is a description of an error which is meant for
+// operators only. When passed to New, it is assigned to the
+// InternalMessage field of the error, which is part of the string
+// returned by Error, but never serialized for clients.
+type InternalMessage string
+
+// mergeDetails promotes the details of the underlying error
+// which are not set on e.
+func (e *Error) mergeDetails(inner *Error) {
+	if len(e.Violations) == 0 && len(inner.Violations) > 0 {
+		e.Violations = append([]FieldViolation(nil), inner.Violations...)
+	}
+
+	for key, value := range inner.Metadata {
+		if _, ok := e.Metadata[key]; ok {
+			continue
+		}
+		if e.Metadata == nil {
+			e.Metadata = Metadata{}
+		}
+		e.Metadata[key] = value
+	}
+}
+
+func (e *Error) addMetadata(md Metadata) {
+	if len(md) == 0 {
+		return
+	}
+
+	if e.Metadata == nil {
+		e.Metadata = make(Metadata, len(md))
+	}
+	for key, value := range md {
+		e.Metadata[key] = value
+	}
+}
@@ -0,0 +1,74 @@
+package err_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+func TestNew_Details(t *testing.T) {
+	e := errors.New(
+		errors.BadRequest,
+		"invalid policy",
+		errors.InternalMessage("rego compilation failed"),
+		errors.FieldViolation{Path: "rego", Code: "syntax", Message: "unexpected token"},
+		[]errors.FieldViolation{{Path: "name", Code: "required"}},
+		errors.Metadata{"line": 3},
+	).(*errors.Error)
+
+	assert.Equal(t, "rego compilation failed", e.InternalMessage)
+	assert.Len(t, e.Violations, 2)
+	assert.Equal(t, errors.Metadata{"line": 3}, e.Metadata)
+	assert.Contains(t, e.Error(), "invalid policy: rego compilation failed: bad request")
+
+	// details of the underlying error are promoted
+	wrapped := errors.New("failed to store policy", e, errors.Metadata{"line": 5, "policy": "example"}).(*errors.Error)
+	assert.Equal(t, e.Violations, wrapped.Violations)
+	assert.Equal(t, errors.Metadata{"line": 5, "policy": "example"}, wrapped.Metadata)
+	assert.Empty(t, wrapped.InternalMessage)
+}
+
+func TestError_MarshalJSON_Details(t *testing.T) {
+	e := errors.New(
+		errors.BadRequest,
+		"invalid request",
+		errors.InternalMessage("secret details"),
+		errors.FieldViolation{Path: "address.zipCode", Code: "len", Message: "must have 5 digits"},
+		errors.Metadata{"tenant": "example"},
+	).(*errors.Error)
+
+	data, err := json.Marshal(e)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret details")
+
+	var decoded errors.Error
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, e.ID, decoded.ID)
+	assert.Equal(t, e.Violations, decoded.Violations)
+	assert.Equal(t, e.Metadata, decoded.Metadata)
+	assert.Empty(t, decoded.InternalMessage)
+}
+
+func TestWriteProblem_Details(t *testing.T) {
+	e := errors.New(
+		errors.BadRequest,
+		errors.InternalMessage("secret details"),
+		errors.FieldViolation{Path: "name", Code: "required"},
+		errors.Metadata{"tenant": "example"},
+	)
+
+	rr := httptest.NewRecorder()
+	errors.WriteProblem(rr, httptest.NewRequest(http.MethodPost, "/", nil), e)
+	assert.NotContains(t, rr.Body.String(), "secret details")
+
+	parsed, err := errors.Parse(rr.Header().Get("Content-Type"), rr.Body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, []errors.FieldViolation{{Path: "name", Code: "required"}}, parsed.Violations)
+	assert.Equal(t, errors.Metadata{"tenant": "example"}, parsed.Metadata)
+}
@@ -3,12 +3,18 @@
 // of error identifiers and their messages.
 // It also supports JSON serialization, so service to
 // service communication can preserve error Kind.
+// Errors can be wrapped and inspected with the standard
+// library functions errors.Is, errors.As and errors.Unwrap.
 package err
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	stderrors "errors"
+	"io"
 	"net/http"
+	"strings"
 )
 
 var separator = ": "
@@ -16,17 +22,30 @@ var separator = ": "
 type Kind int
 
 const (
-	Unknown      Kind = iota // Unknown error.
-	BadRequest               // BadRequest specifies invalid arguments or operation.
-	Unauthorized             // Unauthorized request.
-	Forbidden                // Forbidden operation.
-	Exist                    // Exist already.
-	NotFound                 // NotFound specifies that a resource does not exist.
-	Timeout                  // Timeout of request.
-	Internal                 // Internal error or inconsistency.
-	ServiceUnavailable
+	Unknown              Kind = iota // Unknown error.
+	BadRequest                       // BadRequest specifies invalid arguments or operation.
+	Unauthorized                     // Unauthorized request.
+	Forbidden                        // Forbidden operation.
+	Exist                            // Exist already.
+	NotFound                         // NotFound specifies that a resource does not exist.
+	Timeout                          // Timeout of request.
+	Internal                         // Internal error or inconsistency.
+	ServiceUnavailable               // ServiceUnavailable specifies that a service is temporarily not available.
+	Conflict                         // Conflict with the current state of a resource.
+	PreconditionFailed               // PreconditionFailed specifies that a request precondition does not hold.
+	UnsupportedMediaType             // UnsupportedMediaType of the request payload.
+	Unprocessable                    // Unprocessable specifies well-formed but semantically invalid input.
+	TooManyRequests                  // TooManyRequests specifies that a rate limit was exceeded.
+	NotImplemented                   // NotImplemented operation.
+	GatewayTimeout                   // GatewayTimeout of an upstream service.
+	Canceled                         // Canceled request, e.g. by the caller.
+	Gone                             // Gone specifies that a resource was deleted permanently.
 )
 
+// StatusClientClosedRequest is the non-standard HTTP status code
+// used for requests canceled by the client.
+const StatusClientClosedRequest = 499
+
 type Error struct {
 	// ID is a unique error identifier.
 	ID string
@@ -34,11 +53,32 @@ type Error struct {
 	// Kind of error returned to the caller.
 	Kind Kind
 
+	// Code is the registered code of the error, if any.
+	Code Code
+
 	// Message is a description of the error.
 	Message string
 
+	// InternalMessage is a description of the error for operators.
+	// It is included in the string returned by Error, but never
+	// serialized for clients.
+	InternalMessage string
+
+	// Violations lists the invalid fields of a request, if any.
+	Violations []FieldViolation
+
+	// Metadata holds additional structured information, if any.
+	Metadata Metadata
+
+	// Items are the errors of the elements of a failed batch
+	// operation, see Multi.
+	Items []Item
+
 	// The underlying error that triggered this one, if any.
 	Err error
+
+	// stack is captured by New if stack capture is enabled.
+	stack []uintptr
 }
 
 func (k Kind) String() string {
@@ -61,11 +101,37 @@ func (k Kind) String() string {
 		return "internal error"
 	case ServiceUnavailable:
 		return "service unavailable"
+	case Conflict:
+		return "conflict"
+	case PreconditionFailed:
+		return "precondition failed"
+	case UnsupportedMediaType:
+		return "unsupported media type"
+	case Unprocessable:
+		return "unprocessable entity"
+	case TooManyRequests:
+		return "too many requests"
+	case NotImplemented:
+		return "not implemented"
+	case GatewayTimeout:
+		return "gateway timeout"
+	case Canceled:
+		return "canceled"
+	case Gone:
+		return "gone"
 	}
 
 	return "unknown error kind"
 }
 
+// Error implements the error interface, so a Kind can be used as
+// sentinel error with the standard library:
+//
+//	if errors.Is(err, errors.NotFound) { ... }
+func (k Kind) Error() string {
+	return k.String()
+}
+
 // New builds an error value from its arguments.
 // There must be at least one argument or New panics.
 // The type of each argument determines its meaning.
@@ -84,13 +150,44 @@ func (k Kind) String() string {
 //	    The underlying error that triggered this one.
 //	string:
 //	    Treated as an error message and assigned to the Message field.
+//	errors.Code:
+//	    The code of the error. The Kind and Message registered for the
+//	    code are used, unless they are given explicitly.
+//	errors.InternalMessage:
+//	    Assigned to the InternalMessage field.
+//	errors.FieldViolation, []errors.FieldViolation:
+//	    Appended to the Violations field.
+//	errors.Metadata:
+//	    Merged into the Metadata field.
+//	*errors.Multi:
+//	    The underlying errors of a batch operation. They are assigned
+//	    to the Items field and their Kind of highest precedence is
+//	    promoted.
+//
+// The Code, Violations and Metadata of an underlying *errors.Error are
+// promoted as well, unless they are set explicitly.
+//
+// If no Kind is given or promoted, errors caused by context.Canceled
+// get the Kind Canceled and errors caused by context.DeadlineExceeded
+// get the Kind Timeout.
+//
+// If stack capture is enabled (see SetStackCapture), the call stack is
+// recorded, unless an underlying *errors.Error already carries one.
 func New(args ...interface{}) error {
+	return newError(args)
+}
+
+// newError implements New. It must be called directly by the exported
+// constructor, so the captured stack starts at its caller.
+func newError(args []interface{}) *Error {
 	if len(args) == 0 {
 		panic("call to errors.New without arguments")
 	}
 
 	e := &Error{}
 	var innerKind = Unknown
+	var inner *Error
+	var hasMessage bool
 	for _, arg := range args {
 		switch arg := arg.(type) {
 		case Kind:
@@ -100,16 +197,53 @@ func New(args ...interface{}) error {
 			e.Err = &errCopy
 			e.ID = errCopy.ID
 			innerKind = errCopy.Kind
+			inner = &errCopy
 			if e.Message == "" {
 				e.Message = errCopy.Message
 			}
+		case *Multi:
+			e.Err = arg
+			e.Items = arg.Items()
+			innerKind = arg.Kind()
 		case error:
 			e.Err = arg
 		case string:
 			e.Message = arg
+			hasMessage = true
+		case Code:
+			e.Code = arg
+		case InternalMessage:
+			e.InternalMessage = string(arg)
+		case FieldViolation:
+			e.Violations = append(e.Violations, arg)
+		case []FieldViolation:
+			e.Violations = append(e.Violations, arg...)
+		case Metadata:
+			e.addMetadata(arg)
+		}
+	}
+
+	if info, ok := LookupCode(e.Code); ok {
+		if e.Kind == Unknown {
+			e.Kind = info.Kind
+		}
+		if !hasMessage && info.Message != "" {
+			e.Message = info.Message
+		}
+	}
+
+	if inner != nil {
+		e.mergeDetails(inner)
+		e.stack = inner.stack
+		if e.Code == "" {
+			e.Code = inner.Code
 		}
 	}
 
+	if e.stack == nil && StackCapture() {
+		e.stack = callers(2)
+	}
+
 	if e.ID == "" {
 		e.ID = NewID()
 	}
@@ -118,13 +252,101 @@ func New(args ...interface{}) error {
 		e.Kind = innerKind
 	}
 
+	if e.Kind == Unknown && e.Err != nil {
+		switch {
+		case stderrors.Is(e.Err, context.Canceled):
+			e.Kind = Canceled
+		case stderrors.Is(e.Err, context.DeadlineExceeded):
+			e.Kind = Timeout
+		}
+	}
+
 	return e
 }
 
-// Is reports whether err is an *Error of the given Kind.
+// Is reports whether the Kind of err is the given Kind, see KindOf.
+// Errors wrapped with fmt.Errorf("...: %w", err) are recognized as well.
+// Only the first *Error with a Kind counts, so an error re-classified by
+// New, e.g. New(Internal, notFoundErr), is Internal and not NotFound.
+// Conflict also matches Exist errors, which are a specific conflict.
+// Errors without *Error in their chain have no Kind, not even Unknown.
+//
+// Unlike Is, the standard library errors.Is(err, kind) matches every
+// *Error of the chain, see Error.Is.
 func Is(kind Kind, err error) bool {
-	cerr, ok := err.(*Error)
-	return ok && cerr.Kind == kind
+	if _, ok := As(err); !ok {
+		return false
+	}
+
+	return kind.matches(KindOf(err))
+}
+
+// KindOf returns the Kind of the first *Error in the chain of err
+// which has a Kind other than Unknown. Both single (Unwrap() error)
+// and multiple (Unwrap() []error) wrapping is followed. The Kind of
+// a *Multi is resolved by precedence, see Multi.
+func KindOf(err error) Kind {
+	switch e := err.(type) {
+	case nil:
+		return Unknown
+	case *Error:
+		if e.Kind != Unknown {
+			return e.Kind
+		}
+	case *Multi:
+		return e.Kind()
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return KindOf(e.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if kind := KindOf(inner); kind != Unknown {
+				return kind
+			}
+		}
+	}
+
+	return Unknown
+}
+
+// matches reports whether an error of Kind other is of Kind k.
+func (k Kind) matches(other Kind) bool {
+	return k == other || (k == Conflict && other == Exist)
+}
+
+// As finds the first *Error in the chain of err.
+func As(err error) (*Error, bool) {
+	var e *Error
+	if stderrors.As(err, &e) {
+		return e, true
+	}
+
+	return nil, false
+}
+
+// Unwrap returns the underlying error, if any.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches target, so errors.Is can be
+// used with a Kind or an *Error as sentinel. A Kind matches errors of
+// that Kind; Conflict also matches Exist errors, which are a specific
+// conflict. An *Error matches if its non-zero ID and Kind are equal.
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case Kind:
+		return t.matches(e.Kind)
+	case *Error:
+		if t.ID == "" && t.Kind == Unknown {
+			return false
+		}
+		return (t.ID == "" || t.ID == e.ID) && (t.Kind == Unknown || t.Kind == e.Kind)
+	}
+
+	return false
 }
 
 // Error returns description of the error.
@@ -140,6 +362,11 @@ func (e *Error) Error() string {
 	b := new(bytes.Buffer)
 	b.WriteString(e.Message)
 
+	if e.InternalMessage != "" {
+		pad(b, separator)
+		b.WriteString(e.InternalMessage)
+	}
+
 	if e.Kind != 0 {
 		pad(b, separator)
 		b.WriteString(e.Kind.String())
@@ -168,6 +395,11 @@ func (e *Error) errorSkipID() string {
 	b := new(bytes.Buffer)
 	b.WriteString(e.Message)
 
+	if e.InternalMessage != "" {
+		pad(b, separator)
+		b.WriteString(e.InternalMessage)
+	}
+
 	if e.Kind != 0 {
 		pad(b, separator)
 		b.WriteString(e.Kind.String())
@@ -202,48 +434,83 @@ func (e *Error) StatusCode() int {
 		return http.StatusInternalServerError
 	case ServiceUnavailable:
 		return http.StatusServiceUnavailable
+	case Conflict:
+		return http.StatusConflict
+	case PreconditionFailed:
+		return http.StatusPreconditionFailed
+	case UnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	case Unprocessable:
+		return http.StatusUnprocessableEntity
+	case TooManyRequests:
+		return http.StatusTooManyRequests
+	case NotImplemented:
+		return http.StatusNotImplemented
+	case GatewayTimeout:
+		return http.StatusGatewayTimeout
+	case Canceled:
+		return StatusClientClosedRequest
+	case Gone:
+		return http.StatusGone
 	default:
 		return http.StatusInternalServerError
 	}
 }
 
+// jsonError is the JSON representation of an Error.
+type jsonError struct {
+	ID         string           `json:"id,omitempty"`
+	Kind       Kind             `json:"kind"`
+	Code       Code             `json:"code,omitempty"`
+	Message    string           `json:"message,omitempty"`
+	Violations []FieldViolation `json:"violations,omitempty"`
+	Metadata   Metadata         `json:"metadata,omitempty"`
+	Items      []Item           `json:"items,omitempty"`
+}
+
 // MarshalJSON returns the JSON representation of an Error.
+// The InternalMessage and the underlying error are not included.
 func (e *Error) MarshalJSON() (data []byte, err error) {
-	var d = struct {
-		ID      string `json:"id,omitempty"`
-		Kind    Kind   `json:"kind"`
-		Message string `json:"message,omitempty"`
-	}{
-		ID:      e.ID,
-		Kind:    e.Kind,
-		Message: e.Message,
+	var d = jsonError{
+		ID:         e.ID,
+		Kind:       e.Kind,
+		Code:       e.Code,
+		Message:    e.Message,
+		Violations: e.Violations,
+		Metadata:   e.Metadata,
+		Items:      e.Items,
 	}
 	return json.Marshal(d)
 }
 
 // UnmarshalJSON decodes a JSON encoded Error.
 func (e *Error) UnmarshalJSON(data []byte) error {
-	var d struct {
-		ID      string `json:"id,omitempty"`
-		Kind    Kind   `json:"kind"`
-		Message string `json:"message,omitempty"`
-	}
+	var d jsonError
 	if err := json.Unmarshal(data, &d); err != nil {
 		return err
 	}
 
 	*e = Error{
-		ID:      d.ID,
-		Kind:    d.Kind,
-		Message: d.Message,
+		ID:         d.ID,
+		Kind:       d.Kind,
+		Code:       d.Code,
+		Message:    d.Message,
+		Violations: d.Violations,
+		Metadata:   d.Metadata,
+		Items:      d.Items,
 	}
 	return nil
 }
 
+// JSON writes the JSON representation of the error with the status code
+// of its Kind, unless a status code is given. It has no access to the
+// request, so the message is not localized; use LocalizedJSON or Respond
+// to write it in the language of the Accept-Language header of the
+// request.
 func JSON(w http.ResponseWriter, err error, statusCode ...int) {
 	var e error
 	var ok bool
-	if e, ok = err.(*Error); !ok {
+	if e, ok = As(err); !ok {
 		e = New(err)
 	}
 
@@ -260,15 +527,75 @@ func JSON(w http.ResponseWriter, err error, statusCode ...int) {
 		code = statusCode[0]
 	}
 
+	w.Header().Set("Content-Type", ContentTypeJSON)
 	w.WriteHeader(code)
-	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(e)
 }
 
+// FromResponse builds an error from an HTTP error response.
+//
+// If the response body contains a JSON encoded Error or RFC 9457
+// Problem Details (see Parse), the returned
+// error wraps it and keeps its ID and Kind, so failures can be traced
+// across service boundaries. Otherwise the Kind is determined by the
+// status code of the response. The args are interpreted as in New
+// and add local context to the returned error.
+//
+// The response body is consumed and replaced with a buffered copy.
+func FromResponse(resp *http.Response, args ...interface{}) error {
+	if remote := decodeResponse(resp); remote != nil {
+		if remote.Kind == Unknown {
+			remote.Kind = GetKind(resp.StatusCode)
+		}
+		return New(append(args, remote)...)
+	}
+
+	if len(args) == 0 {
+		args = []interface{}{resp.Status}
+	}
+
+	return New(append([]interface{}{GetKind(resp.StatusCode)}, args...)...)
+}
+
+func decodeResponse(resp *http.Response) *Error {
+	if resp.Body == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	remote, err := Parse(contentType, body)
+	if err != nil {
+		return nil
+	}
+
+	// arbitrary JSON bodies decode into an empty Error
+	if remote.ID == "" && !strings.HasPrefix(contentType, ContentTypeProblem) {
+		return nil
+	}
+
+	return remote
+}
+
 // Temporary reports if an Error is temporary and
 // whether the request can be retried.
 func (e *Error) Temporary() bool {
-	return e != nil && (e.Kind == Internal || e.Kind == Timeout)
+	if e == nil {
+		return false
+	}
+
+	switch e.Kind {
+	case Internal, Timeout, ServiceUnavailable, TooManyRequests, GatewayTimeout:
+		return true
+	}
+
+	return false
 }
 
 // GetKind returns error kind determined
@@ -291,6 +618,22 @@ func GetKind(statusCode int) Kind {
 		return Internal
 	case http.StatusServiceUnavailable:
 		return ServiceUnavailable
+	case http.StatusPreconditionFailed:
+		return PreconditionFailed
+	case http.StatusUnsupportedMediaType:
+		return UnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return Unprocessable
+	case http.StatusTooManyRequests:
+		return TooManyRequests
+	case http.StatusNotImplemented:
+		return NotImplemented
+	case http.StatusGatewayTimeout:
+		return GatewayTimeout
+	case http.StatusGone:
+		return Gone
+	case StatusClientClosedRequest:
+		return Canceled
 	default:
 		return Unknown
 	}
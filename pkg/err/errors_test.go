@@ -1,7 +1,9 @@
 package err_test
 
 import (
+	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -138,6 +140,68 @@ func TestIs(t *testing.T) {
 	e = errors.New(errors.Timeout, errors.New(errors.Unauthorized))
 	assert.IsType(t, &errors.Error{}, e)
 	assert.True(t, errors.Is(errors.Timeout, e))
+
+	// only the first kind counts, unlike with the standard errors.Is
+	assert.False(t, errors.Is(errors.Unauthorized, e))
+	assert.ErrorIs(t, e, errors.Unauthorized)
+	assert.False(t, errors.Is(errors.NotFound, errors.New(errors.Internal, "db failed", errors.New(errors.NotFound))))
+	assert.False(t, errors.Is(errors.NotFound, e))
+	assert.Equal(t, errors.Timeout, errors.KindOf(e))
+}
+
+func TestIs_Wrapped(t *testing.T) {
+	e := errors.New(errors.NotFound, "item does not exist")
+	wrapped := fmt.Errorf("failed to load item: %w", e)
+
+	assert.True(t, errors.Is(errors.NotFound, wrapped))
+	assert.False(t, errors.Is(errors.Internal, wrapped))
+	assert.Equal(t, errors.NotFound, errors.KindOf(wrapped))
+
+	// multiple wrapped errors
+	joined := stderrors.Join(fmt.Errorf("plain"), wrapped)
+	assert.True(t, errors.Is(errors.NotFound, joined))
+
+	// errors without kind
+	assert.False(t, errors.Is(errors.Unknown, fmt.Errorf("plain")))
+	assert.True(t, errors.Is(errors.Unknown, errors.New("structured")))
+	assert.False(t, errors.Is(errors.Unknown, nil))
+	assert.Equal(t, errors.Unknown, errors.KindOf(nil))
+}
+
+func TestError_Unwrap(t *testing.T) {
+	e := errors.New(errors.Timeout, "request failed", context.DeadlineExceeded)
+	assert.ErrorIs(t, e, context.DeadlineExceeded)
+
+	inner := errors.New(errors.Unauthorized, "no way out")
+	e = fmt.Errorf("outer: %w", errors.New(errors.BadRequest, inner))
+
+	var ec *errors.Error
+	assert.True(t, stderrors.As(e, &ec))
+	assert.Equal(t, errors.BadRequest, ec.Kind)
+
+	ec, ok := errors.As(e)
+	assert.True(t, ok)
+	assert.Equal(t, errors.BadRequest, ec.Kind)
+
+	_, ok = errors.As(fmt.Errorf("plain"))
+	assert.False(t, ok)
+}
+
+func TestError_IsSentinel(t *testing.T) {
+	inner := errors.New(errors.Unauthorized, "no way out")
+	e := fmt.Errorf("outer: %w", errors.New(errors.BadRequest, "bad request", inner))
+
+	// any error of the chain matches
+	assert.ErrorIs(t, e, errors.BadRequest)
+	assert.ErrorIs(t, e, errors.Unauthorized)
+	assert.NotErrorIs(t, e, errors.NotFound)
+
+	// matching by ID and Kind
+	ec := inner.(*errors.Error)
+	assert.ErrorIs(t, e, &errors.Error{ID: ec.ID})
+	assert.ErrorIs(t, e, &errors.Error{Kind: errors.Unauthorized})
+	assert.NotErrorIs(t, e, &errors.Error{ID: ec.ID, Kind: errors.NotFound})
+	assert.NotErrorIs(t, e, &errors.Error{})
 }
 
 func TestError_StatusCode(t *testing.T) {
@@ -191,6 +255,51 @@ func TestError_StatusCode(t *testing.T) {
 			kind: errors.ServiceUnavailable,
 			code: http.StatusServiceUnavailable,
 		},
+		{
+			name: "conflict",
+			kind: errors.Conflict,
+			code: http.StatusConflict,
+		},
+		{
+			name: "precondition failed",
+			kind: errors.PreconditionFailed,
+			code: http.StatusPreconditionFailed,
+		},
+		{
+			name: "unsupported media type",
+			kind: errors.UnsupportedMediaType,
+			code: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "unprocessable",
+			kind: errors.Unprocessable,
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "too many requests",
+			kind: errors.TooManyRequests,
+			code: http.StatusTooManyRequests,
+		},
+		{
+			name: "not implemented",
+			kind: errors.NotImplemented,
+			code: http.StatusNotImplemented,
+		},
+		{
+			name: "gateway timeout",
+			kind: errors.GatewayTimeout,
+			code: http.StatusGatewayTimeout,
+		},
+		{
+			name: "canceled",
+			kind: errors.Canceled,
+			code: errors.StatusClientClosedRequest,
+		},
+		{
+			name: "gone",
+			kind: errors.Gone,
+			code: http.StatusGone,
+		},
 	}
 
 	for _, test := range tests {
@@ -235,6 +344,52 @@ func TestError_Temporary(t *testing.T) {
 	ec, ok = e.(*errors.Error)
 	assert.True(t, ok)
 	assert.True(t, ec.Temporary())
+
+	for _, kind := range []errors.Kind{errors.Timeout, errors.ServiceUnavailable, errors.TooManyRequests, errors.GatewayTimeout} {
+		ec = errors.New(kind).(*errors.Error)
+		assert.True(t, ec.Temporary(), kind.String())
+	}
+
+	for _, kind := range []errors.Kind{errors.Conflict, errors.PreconditionFailed, errors.UnsupportedMediaType, errors.Unprocessable, errors.NotImplemented, errors.Canceled, errors.Gone} {
+		ec = errors.New(kind).(*errors.Error)
+		assert.False(t, ec.Temporary(), kind.String())
+	}
+}
+
+func TestKind_RoundTrip(t *testing.T) {
+	for kind := errors.ServiceUnavailable; kind <= errors.Gone; kind++ {
+		t.Run(kind.String(), func(t *testing.T) {
+			ec := errors.New(kind, "round trip").(*errors.Error)
+			assert.True(t, errors.Is(kind, errors.New(errors.GetKind(ec.StatusCode()))))
+
+			data, err := ec.MarshalJSON()
+			assert.NoError(t, err)
+			decoded := &errors.Error{}
+			assert.NoError(t, decoded.UnmarshalJSON(data))
+			assert.Equal(t, kind, decoded.Kind)
+		})
+	}
+
+	// Exist is a specific Conflict
+	e := errors.New(errors.Exist, "account already exists")
+	assert.Equal(t, http.StatusConflict, e.(*errors.Error).StatusCode())
+	assert.ErrorIs(t, e, errors.Conflict)
+	assert.True(t, errors.Is(errors.Conflict, e))
+	assert.NotErrorIs(t, errors.New(errors.Conflict), errors.Exist)
+	assert.False(t, errors.Is(errors.Exist, errors.New(errors.Conflict)))
+	assert.Equal(t, errors.Exist, errors.GetKind(http.StatusConflict))
+}
+
+func TestNew_Context(t *testing.T) {
+	e := errors.New("request aborted", context.Canceled)
+	assert.True(t, errors.Is(errors.Canceled, e))
+
+	e = errors.New("request aborted", fmt.Errorf("query failed: %w", context.DeadlineExceeded))
+	assert.True(t, errors.Is(errors.Timeout, e))
+
+	// an explicit kind is not overwritten
+	e = errors.New(errors.GatewayTimeout, "upstream too slow", context.DeadlineExceeded)
+	assert.True(t, errors.Is(errors.GatewayTimeout, e))
 }
 
 func TestGetKind(t *testing.T) {
@@ -288,6 +443,46 @@ func TestGetKind(t *testing.T) {
 			code: http.StatusServiceUnavailable,
 			kind: errors.ServiceUnavailable,
 		},
+		{
+			name: "precondition failed",
+			code: http.StatusPreconditionFailed,
+			kind: errors.PreconditionFailed,
+		},
+		{
+			name: "unsupported media type",
+			code: http.StatusUnsupportedMediaType,
+			kind: errors.UnsupportedMediaType,
+		},
+		{
+			name: "unprocessable",
+			code: http.StatusUnprocessableEntity,
+			kind: errors.Unprocessable,
+		},
+		{
+			name: "too many requests",
+			code: http.StatusTooManyRequests,
+			kind: errors.TooManyRequests,
+		},
+		{
+			name: "not implemented",
+			code: http.StatusNotImplemented,
+			kind: errors.NotImplemented,
+		},
+		{
+			name: "gateway timeout",
+			code: http.StatusGatewayTimeout,
+			kind: errors.GatewayTimeout,
+		},
+		{
+			name: "canceled",
+			code: errors.StatusClientClosedRequest,
+			kind: errors.Canceled,
+		},
+		{
+			name: "gone",
+			code: http.StatusGone,
+			kind: errors.Gone,
+		},
 	}
 
 	for _, test := range tests {
@@ -354,6 +549,15 @@ func TestJSON(t *testing.T) {
 				Err:     fmt.Errorf("embedded error"),
 			},
 		},
+		{
+			name:         "wrapped structured error",
+			err:          fmt.Errorf("wrapped: %w", errors.New(errors.Forbidden, "structured error")),
+			responseCode: http.StatusForbidden,
+			responseError: &errors.Error{
+				Kind:    errors.Forbidden,
+				Message: "structured error",
+			},
+		},
 		{
 			name:         "structured error with kind only",
 			err:          errors.New(errors.Timeout),
@@ -384,3 +588,44 @@ func TestJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestFromResponse(t *testing.T) {
+	remote := errors.New(errors.NotFound, "policy not found").(*errors.Error)
+
+	// response with a JSON encoded error
+	rr := httptest.NewRecorder()
+	errors.JSON(rr, remote)
+	resp := rr.Result()
+
+	e := errors.FromResponse(resp, "failed to evaluate policy")
+	assert.True(t, errors.Is(errors.NotFound, e))
+	ec, ok := e.(*errors.Error)
+	assert.True(t, ok)
+	assert.Equal(t, remote.ID, ec.ID)
+	assert.Equal(t, "failed to evaluate policy", ec.Message)
+	assert.Contains(t, e.Error(), "failed to evaluate policy: not found: policy not found")
+
+	// the body stays readable
+	var body errors.Error
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, remote.ID, body.ID)
+
+	// remote error without kind gets the kind of the status code
+	rr = httptest.NewRecorder()
+	errors.JSON(rr, errors.New("remote"), http.StatusServiceUnavailable)
+	e = errors.FromResponse(rr.Result())
+	assert.True(t, errors.Is(errors.ServiceUnavailable, e))
+	assert.Contains(t, e.Error(), "remote")
+
+	// response without error body
+	rr = httptest.NewRecorder()
+	rr.WriteHeader(http.StatusForbidden)
+	_, _ = rr.WriteString("access denied")
+	e = errors.FromResponse(rr.Result())
+	assert.True(t, errors.Is(errors.Forbidden, e))
+	assert.Contains(t, e.Error(), "403 Forbidden")
+
+	e = errors.FromResponse(rr.Result(), errors.Internal, "unexpected response")
+	assert.True(t, errors.Is(errors.Internal, e))
+	assert.Contains(t, e.Error(), "unexpected response")
+}
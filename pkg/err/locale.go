@@ -0,0 +1,210 @@
+package err
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+// builtinLocales holds the messages of all kinds in English, German
+// and French.
+//
+//go:embed locales/*.json
+var builtinLocales embed.FS
+
+// messageCatalog holds the user-facing messages of a language.
+// Messages may contain placeholders like {policy}, which are replaced
+// with the value of the same key of the error Metadata.
+type messageCatalog struct {
+	// Kinds maps the name of a Kind (see Kind.String) to its message.
+	Kinds map[string]string `json:"kinds"`
+
+	// Codes maps a registered Code to its message.
+	Codes map[Code]string `json:"codes"`
+
+	// Messages maps free-text messages, as passed to New, to their
+	// translation.
+	Messages map[string]string `json:"messages"`
+}
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[language.Tag]*messageCatalog{}
+	// languages lists the tags of all catalogs, English first, so
+	// it is the fallback of the matcher.
+	languages = []language.Tag{language.English}
+	matcher   = language.NewMatcher(languages)
+)
+
+func init() {
+	if err := LoadLocales(builtinLocales, "locales"); err != nil {
+		panic(err)
+	}
+}
+
+// LoadLocales reads message catalogs from the files named <language>.json
+// (e.g. de.json) in the given directory and merges them into the loaded
+// ones. A catalog file has the form:
+//
+//	{
+//	  "kinds": {"not found": "Die Ressource wurde nicht gefunden."},
+//	  "codes": {"POLICY_NOT_FOUND": "Die Richtlinie {policy} wurde nicht gefunden."},
+//	  "messages": {"account already exists": "Das Konto existiert bereits."}
+//	}
+//
+// Services can embed their own catalogs and load them on start.
+func LoadLocales(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+
+		var catalog messageCatalog
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return fmt.Errorf("failed to decode message catalog %s: %w", file, err)
+		}
+
+		lang := strings.TrimSuffix(path.Base(file), ".json")
+		if err := addCatalog(lang, &catalog); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddMessages adds the messages of codes in the given language,
+// e.g. "de", to the loaded catalogs.
+func AddMessages(lang string, messages map[Code]string) error {
+	return addCatalog(lang, &messageCatalog{Codes: messages})
+}
+
+// AddTranslations adds translations of free-text messages in the given
+// language, e.g. "de", to the loaded catalogs. The keys are the messages
+// as passed to New.
+func AddTranslations(lang string, messages map[string]string) error {
+	return addCatalog(lang, &messageCatalog{Messages: messages})
+}
+
+func addCatalog(lang string, catalog *messageCatalog) error {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return fmt.Errorf("invalid language %q: %w", lang, err)
+	}
+
+	localesMu.Lock()
+	defer localesMu.Unlock()
+
+	existing, ok := locales[tag]
+	if !ok {
+		existing = &messageCatalog{}
+		locales[tag] = existing
+		if tag != language.English {
+			languages = append(languages, tag)
+			matcher = language.NewMatcher(languages)
+		}
+	}
+
+	if existing.Kinds == nil {
+		existing.Kinds = map[string]string{}
+	}
+	for kind, msg := range catalog.Kinds {
+		existing.Kinds[kind] = msg
+	}
+
+	if existing.Codes == nil {
+		existing.Codes = map[Code]string{}
+	}
+	for code, msg := range catalog.Codes {
+		existing.Codes[code] = msg
+	}
+
+	if existing.Messages == nil {
+		existing.Messages = map[string]string{}
+	}
+	for text, msg := range catalog.Messages {
+		existing.Messages[text] = msg
+	}
+
+	return nil
+}
+
+// Localize returns a copy of the error with the message in the language
+// preferred by the given Accept-Language header value. Languages without
+// catalog fall back to English.
+//
+// The message of the registered Code of the error is used if there is one
+// in the catalog. Otherwise, errors without message get the message of
+// their Kind and free-text messages get their translation from the
+// messages of the catalog (see AddTranslations). Messages without
+// translation are kept. The error itself is not modified, so logs keep
+// the untranslated message.
+func (e *Error) Localize(acceptLanguage string) *Error {
+	localized, _ := e.localize(acceptLanguage)
+	return localized
+}
+
+// localize returns the localized copy of the error and the language of
+// its message, which is empty if the message was not localized.
+func (e *Error) localize(acceptLanguage string) (*Error, string) {
+	if acceptLanguage == "" {
+		return e, ""
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return e, ""
+	}
+
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+
+	_, index, _ := matcher.Match(tags...)
+	tag := languages[index]
+	catalog := locales[tag]
+	if catalog == nil {
+		return e, ""
+	}
+
+	msg, ok := catalog.Codes[e.Code]
+	if !ok && e.Message == "" {
+		msg, ok = catalog.Kinds[e.Kind.String()]
+	} else if !ok {
+		msg, ok = catalog.Messages[e.Message]
+	}
+	if !ok {
+		return e, ""
+	}
+
+	localized := *e
+	localized.Message = expand(msg, e.Metadata)
+
+	return &localized, tag.String()
+}
+
+// expand replaces the placeholders of the message with the values of
+// the metadata.
+func expand(msg string, md Metadata) string {
+	if len(md) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+
+	oldnew := make([]string, 0, 2*len(md))
+	for key, value := range md {
+		oldnew = append(oldnew, "{"+key+"}", fmt.Sprint(value))
+	}
+
+	return strings.NewReplacer(oldnew...).Replace(msg)
+}
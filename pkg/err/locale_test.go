@@ -0,0 +1,166 @@
+package err_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+var codeTenantLocked = errors.RegisterCode(errors.CodeInfo{
+	Code:    "TENANT_LOCKED",
+	Kind:    errors.Forbidden,
+	Message: "tenant {tenant} is locked",
+})
+
+var codeQuotaReached = errors.RegisterCode(errors.CodeInfo{
+	Code: "QUOTA_REACHED",
+	Kind: errors.TooManyRequests,
+})
+
+func init() {
+	err := errors.LoadLocales(fstest.MapFS{
+		"locales/de.json": {Data: []byte(`{
+			"codes": {"TENANT_LOCKED": "Mandant {tenant} ist gesperrt."},
+			"messages": {"account already exists": "Das Konto existiert bereits."}
+		}`)},
+	}, "locales")
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestError_Localize(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		acceptLanguage string
+		message        string
+	}{
+		{
+			name:           "kind message",
+			err:            errors.New(errors.NotFound),
+			acceptLanguage: "de-DE,de;q=0.9,en;q=0.8",
+			message:        "Die Ressource wurde nicht gefunden.",
+		},
+		{
+			name:           "french",
+			err:            errors.New(errors.NotFound),
+			acceptLanguage: "fr-CH, fr;q=0.9",
+			message:        "La ressource est introuvable.",
+		},
+		{
+			name:           "fallback to english",
+			err:            errors.New(errors.NotFound),
+			acceptLanguage: "ja",
+			message:        "The resource was not found.",
+		},
+		{
+			name:           "no accept language",
+			err:            errors.New(errors.NotFound),
+			acceptLanguage: "",
+			message:        "",
+		},
+		{
+			name:           "free text is kept",
+			err:            errors.New(errors.NotFound, "policy not found"),
+			acceptLanguage: "de",
+			message:        "policy not found",
+		},
+		{
+			name:           "free text with translation",
+			err:            errors.New(errors.Exist, "account already exists"),
+			acceptLanguage: "de",
+			message:        "Das Konto existiert bereits.",
+		},
+		{
+			name:           "code with placeholder",
+			err:            errors.New(codeTenantLocked, errors.Metadata{"tenant": "acme"}),
+			acceptLanguage: "de",
+			message:        "Mandant acme ist gesperrt.",
+		},
+		{
+			name:           "code without translation",
+			err:            errors.New(codeTenantLocked, errors.Metadata{"tenant": "acme"}),
+			acceptLanguage: "fr",
+			message:        "tenant {tenant} is locked",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := test.err.(*errors.Error)
+			original := e.Message
+
+			localized := e.Localize(test.acceptLanguage)
+			assert.Equal(t, test.message, localized.Message)
+			assert.Equal(t, e.ID, localized.ID)
+			assert.Equal(t, original, e.Message)
+		})
+	}
+}
+
+func TestAddMessages(t *testing.T) {
+	require.NoError(t, errors.AddMessages("fr", map[errors.Code]string{codeQuotaReached: "Quota atteint."}))
+
+	e := errors.New(codeQuotaReached).(*errors.Error)
+	assert.Equal(t, "Quota atteint.", e.Localize("fr").Message)
+
+	assert.Error(t, errors.AddMessages("not a language!", nil))
+}
+
+func TestAddTranslations(t *testing.T) {
+	require.NoError(t, errors.AddTranslations("fr", map[string]string{"quota reached": "Quota atteint."}))
+
+	e := errors.New(errors.TooManyRequests, "quota reached").(*errors.Error)
+	assert.Equal(t, "Quota atteint.", e.Localize("fr").Message)
+	assert.Equal(t, "quota reached", e.Localize("de").Message)
+}
+
+func TestLocalizedJSON(t *testing.T) {
+	e := errors.New(errors.Exist, "account already exists")
+
+	req := httptest.NewRequest(http.MethodPost, "/accounts", nil)
+	req.Header.Set("Accept-Language", "de")
+
+	rr := httptest.NewRecorder()
+	errors.LocalizedJSON(rr, req, e)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, errors.ContentTypeJSON, rr.Header().Get("Content-Type"))
+	assert.Equal(t, "de", rr.Header().Get("Content-Language"))
+
+	parsed, err := errors.Parse(rr.Header().Get("Content-Type"), rr.Body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "Das Konto existiert bereits.", parsed.Message)
+	assert.Equal(t, "account already exists", e.(*errors.Error).Message)
+
+	// without request, the message is not localized
+	rr = httptest.NewRecorder()
+	errors.LocalizedJSON(rr, nil, e)
+	assert.Empty(t, rr.Header().Get("Content-Language"))
+	assert.Contains(t, rr.Body.String(), "account already exists")
+}
+
+func TestRespond_Localized(t *testing.T) {
+	e := errors.New(errors.Forbidden, errors.InternalMessage("role missing"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "de")
+
+	rr := httptest.NewRecorder()
+	errors.Respond(rr, req, e)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Equal(t, "de", rr.Header().Get("Content-Language"))
+
+	parsed, err := errors.Parse(rr.Header().Get("Content-Type"), rr.Body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "Sie sind nicht berechtigt, diese Aktion auszuführen.", parsed.Message)
+
+	// the original error is not modified
+	assert.Empty(t, e.(*errors.Error).Message)
+}
@@ -0,0 +1,106 @@
+package err
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// Causes returns the messages of the chain of underlying errors,
+// starting with the error directly wrapped by e. Each *Error in the
+// chain contributes its own message and Kind only, the first error
+// of another type ends the chain with its full message.
+func (e *Error) Causes() []string {
+	var causes []string
+
+	for err := e.Err; err != nil; {
+		cerr, ok := err.(*Error)
+		if !ok {
+			causes = append(causes, err.Error())
+			break
+		}
+
+		cause := cerr.Message
+		if cerr.InternalMessage != "" {
+			cause += separator + cerr.InternalMessage
+		}
+		if cerr.Kind != Unknown {
+			if cause != "" {
+				cause += separator
+			}
+			cause += cerr.Kind.String()
+		}
+		causes = append(causes, cause)
+		err = cerr.Err
+	}
+
+	return causes
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler, so the error
+// is logged with its ID, Kind, messages, causes, details and stack
+// as separate fields when passed to zap.Object.
+func (e *Error) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("id", e.ID)
+	enc.AddString("kind", e.Kind.String())
+
+	if e.Message != "" {
+		enc.AddString("message", e.Message)
+	}
+	if e.InternalMessage != "" {
+		enc.AddString("internalMessage", e.InternalMessage)
+	}
+	if causes := e.Causes(); len(causes) > 0 {
+		if err := enc.AddArray("causes", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, cause := range causes {
+				arr.AppendString(cause)
+			}
+			return nil
+		})); err != nil {
+			return err
+		}
+	}
+	if len(e.Violations) > 0 {
+		if err := enc.AddReflected("violations", e.Violations); err != nil {
+			return err
+		}
+	}
+	if len(e.Metadata) > 0 {
+		if err := enc.AddReflected("metadata", e.Metadata); err != nil {
+			return err
+		}
+	}
+	if stack := e.StackTrace(); stack != "" {
+		enc.AddString("stack", stack)
+	}
+
+	return nil
+}
+
+// MarshalLog implements logr.Marshaler and returns the fields
+// of the error in the same form as MarshalLogObject.
+func (e *Error) MarshalLog() interface{} {
+	fields := map[string]interface{}{
+		"id":   e.ID,
+		"kind": e.Kind.String(),
+	}
+
+	if e.Message != "" {
+		fields["message"] = e.Message
+	}
+	if e.InternalMessage != "" {
+		fields["internalMessage"] = e.InternalMessage
+	}
+	if causes := e.Causes(); len(causes) > 0 {
+		fields["causes"] = causes
+	}
+	if len(e.Violations) > 0 {
+		fields["violations"] = e.Violations
+	}
+	if len(e.Metadata) > 0 {
+		fields["metadata"] = e.Metadata
+	}
+	if stack := e.StackTrace(); stack != "" {
+		fields["stack"] = stack
+	}
+
+	return fields
+}
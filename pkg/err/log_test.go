@@ -0,0 +1,84 @@
+package err_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+)
+
+func TestSetStackCapture(t *testing.T) {
+	defer errors.SetStackCapture(errors.StackCapture())
+
+	errors.SetStackCapture(false)
+	e := errors.New(errors.Internal, "no stack").(*errors.Error)
+	assert.Empty(t, e.StackTrace())
+
+	errors.SetStackCapture(true)
+	e = errors.New(errors.Internal, "with stack").(*errors.Error)
+	assert.Contains(t, e.StackTrace(), "err_test.TestSetStackCapture")
+
+	// the stack of the underlying error is kept
+	wrapped := errors.New("wrapped", e).(*errors.Error)
+	assert.Equal(t, e.StackTrace(), wrapped.StackTrace())
+}
+
+func TestError_Causes(t *testing.T) {
+	inner := errors.New(errors.NotFound, "policy not found", fmt.Errorf("no rows"))
+	e := errors.New("failed to evaluate policy", errors.InternalMessage("lookup failed"), inner).(*errors.Error)
+
+	assert.Equal(t, []string{"policy not found: not found", "no rows"}, e.Causes())
+}
+
+func TestError_MarshalLogObject(t *testing.T) {
+	defer errors.SetStackCapture(errors.StackCapture())
+	errors.SetStackCapture(false)
+
+	e := errors.New(
+		errors.BadRequest,
+		"invalid request",
+		errors.InternalMessage("decoding failed"),
+		errors.Metadata{"tenant": "example"},
+		fmt.Errorf("unexpected EOF"),
+	).(*errors.Error)
+
+	enc := zapcore.NewMapObjectEncoder()
+	require.NoError(t, e.MarshalLogObject(enc))
+	assert.Equal(t, e.ID, enc.Fields["id"])
+	assert.Equal(t, "bad request", enc.Fields["kind"])
+	assert.Equal(t, "invalid request", enc.Fields["message"])
+	assert.Equal(t, "decoding failed", enc.Fields["internalMessage"])
+	assert.Equal(t, []interface{}{"unexpected EOF"}, enc.Fields["causes"])
+	assert.Equal(t, errors.Metadata{"tenant": "example"}, enc.Fields["metadata"])
+	assert.NotContains(t, enc.Fields, "stack")
+}
+
+func TestError_Logging(t *testing.T) {
+	defer errors.SetStackCapture(errors.StackCapture())
+	errors.SetStackCapture(true)
+
+	buf := new(bytes.Buffer)
+	logger, err := logr.New("info", false, buf)
+	require.NoError(t, err)
+
+	e := errors.New(errors.Internal, "failed to store policy", fmt.Errorf("connection refused"))
+	logger.Error(fmt.Errorf("wrapped: %w", e), "request failed")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+
+	details, ok := entry[logr.ErrorDetailsKey].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, e.(*errors.Error).ID, details["id"])
+	assert.Equal(t, "internal error", details["kind"])
+	assert.Equal(t, "failed to store policy", details["message"])
+	assert.Equal(t, []interface{}{"connection refused"}, details["causes"])
+	assert.Contains(t, details["stack"], "err_test.TestError_Logging")
+}
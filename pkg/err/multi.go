@@ -0,0 +1,203 @@
+package err
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// kindPrecedence orders the kinds from highest to lowest precedence,
+// see Multi. Kinds which are not listed have the lowest precedence.
+var kindPrecedence = []Kind{
+	Internal,
+	ServiceUnavailable,
+	GatewayTimeout,
+	Timeout,
+	NotImplemented,
+	TooManyRequests,
+	Unauthorized,
+	Forbidden,
+	Conflict,
+	Exist,
+	PreconditionFailed,
+	NotFound,
+	Gone,
+	UnsupportedMediaType,
+	Unprocessable,
+	BadRequest,
+	Canceled,
+}
+
+// Item is a single failure of a batch operation, identified either by
+// the index of the failed element or by its key.
+type Item struct {
+	// Index of the failed element; nil for keyed items.
+	Index *int `json:"index,omitempty"`
+
+	// Key of the failed element; empty for indexed items.
+	Key string `json:"key,omitempty"`
+
+	// Err is the error of the element.
+	Err *Error `json:"error"`
+}
+
+// Multi collects the errors of a batch operation, e.g. a bulk cache
+// write, by index or key. It is safe for concurrent use.
+//
+// Multi implements error and Unwrap() []error, so it works with the
+// standard library errors.Is, errors.As and errors.Join. Passed to New,
+// the items are added to the returned *Error, which gets the Kind of
+// highest precedence among the items unless another Kind is given:
+//
+//	Internal, ServiceUnavailable, GatewayTimeout, Timeout, NotImplemented,
+//	TooManyRequests, Unauthorized, Forbidden, Conflict, Exist,
+//	PreconditionFailed, NotFound, Gone, UnsupportedMediaType,
+//	Unprocessable, BadRequest, Canceled
+//
+// Server-side failures take precedence over client errors, so a batch
+// which failed partially due to an outage is reported as temporary.
+// Items of Kind Unknown count as Internal.
+type Multi struct {
+	mu    sync.Mutex
+	items []Item
+}
+
+// Add records the error of the element at the given index.
+// Nil errors are ignored.
+func (m *Multi) Add(index int, err error) {
+	if err == nil {
+		return
+	}
+
+	m.add(Item{Index: &index, Err: toError(err)})
+}
+
+// AddKey records the error of the element with the given key.
+// Nil errors are ignored.
+func (m *Multi) AddKey(key string, err error) {
+	if err == nil {
+		return
+	}
+
+	m.add(Item{Key: key, Err: toError(err)})
+}
+
+func (m *Multi) add(item Item) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.items = append(m.items, item)
+}
+
+// Len returns the number of recorded errors.
+func (m *Multi) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.items)
+}
+
+// Items returns the recorded errors in the order they were added.
+func (m *Multi) Items() []Item {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Item(nil), m.items...)
+}
+
+// Err returns nil if no errors were recorded. Otherwise, it returns an
+// *Error built by New from the given arguments and m, e.g.
+//
+//	return failed.Err("failed to write cache entries")
+func (m *Multi) Err(args ...interface{}) error {
+	if m.Len() == 0 {
+		return nil
+	}
+
+	return New(append(args, m)...)
+}
+
+// Kind returns the Kind of highest precedence among the recorded errors.
+func (m *Multi) Kind() Kind {
+	kind := Unknown
+	rank := len(kindPrecedence) + 1
+
+	for _, item := range m.Items() {
+		k := item.Err.Kind
+		if k == Unknown {
+			k = Internal
+		}
+		if r := precedence(k); r < rank {
+			kind, rank = k, r
+		}
+	}
+
+	return kind
+}
+
+func precedence(kind Kind) int {
+	for i, k := range kindPrecedence {
+		if k == kind {
+			return i
+		}
+	}
+
+	return len(kindPrecedence)
+}
+
+// Error returns the messages of all recorded errors.
+func (m *Multi) Error() string {
+	items := m.Items()
+
+	b := new(bytes.Buffer)
+	if len(items) == 1 {
+		b.WriteString("1 error occurred")
+	} else {
+		fmt.Fprintf(b, "%d errors occurred", len(items))
+	}
+
+	for i, item := range items {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteRune('[')
+		b.WriteString(item.id())
+		b.WriteString("] ")
+		b.WriteString(item.Err.errorSkipID())
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the recorded errors.
+func (m *Multi) Unwrap() []error {
+	items := m.Items()
+
+	errs := make([]error, 0, len(items))
+	for _, item := range items {
+		errs = append(errs, item.Err)
+	}
+
+	return errs
+}
+
+// id returns the index or key of the item.
+func (i Item) id() string {
+	if i.Index != nil {
+		return strconv.Itoa(*i.Index)
+	}
+
+	return strconv.Quote(i.Key)
+}
+
+// toError returns err as *Error, keeping the Kind of a wrapped *Error.
+func toError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+
+	return New(KindOf(err), err).(*Error)
+}
@@ -0,0 +1,128 @@
+package err_test
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+func TestMulti_Kind(t *testing.T) {
+	tests := []struct {
+		name  string
+		kinds []errors.Kind
+		kind  errors.Kind
+	}{
+		{"single", []errors.Kind{errors.NotFound}, errors.NotFound},
+		{"same kind", []errors.Kind{errors.BadRequest, errors.BadRequest}, errors.BadRequest},
+		{"server error wins", []errors.Kind{errors.BadRequest, errors.ServiceUnavailable, errors.NotFound}, errors.ServiceUnavailable},
+		{"forbidden over not found", []errors.Kind{errors.NotFound, errors.Forbidden}, errors.Forbidden},
+		{"not found over gone", []errors.Kind{errors.Gone, errors.NotFound}, errors.NotFound},
+		{"gone over bad request", []errors.Kind{errors.BadRequest, errors.Gone}, errors.Gone},
+		{"unknown is internal", []errors.Kind{errors.BadRequest, errors.Unknown}, errors.Internal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var m errors.Multi
+			for i, kind := range test.kinds {
+				m.Add(i, errors.New(kind, fmt.Sprintf("element %d", i)))
+			}
+			assert.Equal(t, test.kind, m.Kind())
+			assert.Equal(t, test.kind, errors.KindOf(&m))
+			assert.True(t, errors.Is(test.kind, m.Err()))
+		})
+	}
+}
+
+func TestMulti_Err(t *testing.T) {
+	var m errors.Multi
+	assert.NoError(t, m.Err("nothing failed"))
+
+	m.Add(0, nil)
+	assert.Equal(t, 0, m.Len())
+
+	m.Add(1, errors.New(errors.BadRequest, "invalid key"))
+	m.AddKey("did:web:example.com", fmt.Errorf("verification failed: %w", errors.New(errors.NotFound, "did not resolvable")))
+	m.AddKey("did:web:other.com", fmt.Errorf("connection refused"))
+
+	err := m.Err("failed to verify credentials")
+	e, ok := errors.As(err)
+	require.True(t, ok)
+	assert.Equal(t, errors.Internal, e.Kind)
+	assert.Equal(t, "failed to verify credentials", e.Message)
+	require.Len(t, e.Items, 3)
+	assert.Equal(t, 1, *e.Items[0].Index)
+	assert.Equal(t, "did:web:example.com", e.Items[1].Key)
+	assert.Equal(t, errors.NotFound, e.Items[1].Err.Kind)
+	assert.Equal(t, errors.Unknown, e.Items[2].Err.Kind)
+	assert.Contains(t, err.Error(), `3 errors occurred: [1] invalid key: bad request; ["did:web:example.com"]`)
+
+	// explicit kind wins
+	assert.True(t, errors.Is(errors.BadRequest, m.Err(errors.BadRequest)))
+
+	// standard library errors.Is and errors.As search the items
+	assert.ErrorIs(t, err, errors.NotFound)
+	assert.ErrorIs(t, err, e.Items[1].Err)
+	joined := stderrors.Join(fmt.Errorf("other"), err)
+	assert.ErrorIs(t, joined, errors.BadRequest)
+	var multi *errors.Multi
+	assert.ErrorAs(t, joined, &multi)
+	assert.Equal(t, 3, multi.Len())
+}
+
+func TestMulti_JSON(t *testing.T) {
+	var m errors.Multi
+	m.Add(0, errors.New(errors.BadRequest, "invalid value", errors.FieldViolation{Path: "value", Code: "required"}))
+	m.AddKey("key2", errors.New(errors.Conflict, "version mismatch"))
+	err := m.Err("bulk write failed")
+
+	rr := httptest.NewRecorder()
+	errors.JSON(rr, err)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	items, ok := body["items"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, items, 2)
+	assert.Equal(t, float64(0), items[0].(map[string]interface{})["index"])
+	assert.Equal(t, "key2", items[1].(map[string]interface{})["key"])
+
+	parsed, perr := errors.Parse(rr.Header().Get("Content-Type"), rr.Body.Bytes())
+	require.NoError(t, perr)
+	require.Len(t, parsed.Items, 2)
+	assert.Equal(t, "invalid value", parsed.Items[0].Err.Message)
+	assert.Equal(t, []errors.FieldViolation{{Path: "value", Code: "required"}}, parsed.Items[0].Err.Violations)
+	assert.Equal(t, errors.Conflict, parsed.Items[1].Err.Kind)
+
+	// problem details carry the items too
+	rr = httptest.NewRecorder()
+	errors.WriteProblem(rr, nil, err)
+	parsed, perr = errors.Parse(rr.Header().Get("Content-Type"), rr.Body.Bytes())
+	require.NoError(t, perr)
+	assert.Len(t, parsed.Items, 2)
+}
+
+func TestMulti_Concurrent(t *testing.T) {
+	var m errors.Multi
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m.Add(i, errors.New(errors.BadRequest))
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 50, m.Len())
+}
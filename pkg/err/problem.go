@@ -0,0 +1,223 @@
+package err
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ContentTypeJSON is the media type of the JSON encoded Error.
+	ContentTypeJSON = "application/json"
+
+	// ContentTypeProblem is the media type of RFC 9457 Problem Details.
+	ContentTypeProblem = "application/problem+json"
+)
+
+// Problem is the RFC 9457 Problem Details representation of an Error.
+//
+// Besides the standard members, it carries the error ID, Kind and
+// details as extension members, so an Error can be restored from it.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// ID is the unique error identifier.
+	ID string `json:"id,omitempty"`
+
+	// Kind is the Kind of the error.
+	Kind *Kind `json:"kind,omitempty"`
+
+	// Code is the registered code of the error, if any.
+	Code Code `json:"code,omitempty"`
+
+	// Violations lists the invalid fields of the request, if any.
+	Violations []FieldViolation `json:"violations,omitempty"`
+
+	// Metadata holds additional information about the error, if any.
+	Metadata Metadata `json:"metadata,omitempty"`
+
+	// Items are the errors of a failed batch operation, if any.
+	Items []Item `json:"items,omitempty"`
+}
+
+// ToProblem returns the Problem Details representation of the error.
+// The instance should identify the request which caused the error
+// and may be empty. The type is the docs URL of the registered code
+// of the error, if there is one.
+func (e *Error) ToProblem(instance string) *Problem {
+	status := e.StatusCode()
+	kind := e.Kind
+
+	problemType := "about:blank"
+	if info, ok := LookupCode(e.Code); ok && info.DocsURL != "" {
+		problemType = info.DocsURL
+	}
+
+	return &Problem{
+		Type:       problemType,
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     e.Message,
+		Instance:   instance,
+		ID:         e.ID,
+		Kind:       &kind,
+		Code:       e.Code,
+		Violations: e.Violations,
+		Metadata:   e.Metadata,
+		Items:      e.Items,
+	}
+}
+
+// ToError returns the Error described by the problem.
+func (p *Problem) ToError() *Error {
+	e := &Error{
+		ID:         p.ID,
+		Kind:       GetKind(p.Status),
+		Code:       p.Code,
+		Message:    p.Detail,
+		Violations: p.Violations,
+		Metadata:   p.Metadata,
+		Items:      p.Items,
+	}
+
+	if p.Kind != nil {
+		e.Kind = *p.Kind
+	}
+
+	return e
+}
+
+// WriteProblem writes the error as RFC 9457 Problem Details
+// with the application/problem+json content type.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error, statusCode ...int) {
+	e, ok := As(err)
+	if !ok {
+		e = New(err).(*Error)
+	}
+
+	var instance string
+	if r != nil {
+		instance = r.URL.Path
+	}
+
+	p := e.ToProblem(instance)
+	if len(statusCode) > 0 {
+		p.Status = statusCode[0]
+		p.Title = http.StatusText(p.Status)
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// Respond writes the error in the format preferred by the client
+// according to the Accept header of the request. Problem Details are
+// written only if the client asks for application/problem+json,
+// otherwise the JSON representation of Error is used.
+//
+// The message is localized according to the Accept-Language header
+// (see Error.Localize) and the Content-Language header is set if it was.
+func Respond(w http.ResponseWriter, r *http.Request, err error, statusCode ...int) {
+	if r == nil {
+		JSON(w, err, statusCode...)
+		return
+	}
+
+	e, ok := As(err)
+	if !ok {
+		e = New(err).(*Error)
+	}
+
+	if localized, lang := e.localize(r.Header.Get("Accept-Language")); lang != "" {
+		w.Header().Set("Content-Language", lang)
+		e = localized
+	}
+
+	if acceptsProblem(r.Header.Get("Accept")) {
+		WriteProblem(w, r, e, statusCode...)
+		return
+	}
+
+	JSON(w, e, statusCode...)
+}
+
+// LocalizedJSON writes the JSON representation of the error like JSON,
+// with the message localized according to the Accept-Language header of
+// the request (see Error.Localize). The Content-Language header is set
+// if the message was localized.
+func LocalizedJSON(w http.ResponseWriter, r *http.Request, err error, statusCode ...int) {
+	if r == nil {
+		JSON(w, err, statusCode...)
+		return
+	}
+
+	e, ok := As(err)
+	if !ok {
+		e = New(err).(*Error)
+	}
+
+	if localized, lang := e.localize(r.Header.Get("Accept-Language")); lang != "" {
+		w.Header().Set("Content-Language", lang)
+		e = localized
+	}
+
+	JSON(w, e, statusCode...)
+}
+
+// Parse decodes an error in either of the supported formats, selected
+// by the given content type. Problem Details which do not carry the
+// error Kind as extension get the Kind of their status.
+func Parse(contentType string, data []byte) (*Error, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	if mediaType == ContentTypeProblem {
+		var p Problem
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, err
+		}
+		return p.ToError(), nil
+	}
+
+	var e Error
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// acceptsProblem reports whether the Accept header prefers Problem
+// Details over plain JSON.
+func acceptsProblem(accept string) bool {
+	problemQ, jsonQ := -1.0, -1.0
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		switch mediaType {
+		case ContentTypeProblem:
+			problemQ = max(problemQ, q)
+		case ContentTypeJSON:
+			jsonQ = max(jsonQ, q)
+		}
+	}
+
+	return problemQ > 0 && problemQ >= jsonQ
+}
@@ -0,0 +1,137 @@
+package err_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+func TestWriteProblem(t *testing.T) {
+	e := errors.New(errors.NotFound, "policy does not exist").(*errors.Error)
+	req := httptest.NewRequest(http.MethodGet, "/v1/tenants/123/policies/example", nil)
+
+	rr := httptest.NewRecorder()
+	errors.WriteProblem(rr, req, e)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, errors.ContentTypeProblem, rr.Header().Get("Content-Type"))
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+	assert.Equal(t, map[string]interface{}{
+		"type":     "about:blank",
+		"title":    "Not Found",
+		"status":   float64(http.StatusNotFound),
+		"detail":   "policy does not exist",
+		"instance": "/v1/tenants/123/policies/example",
+		"id":       e.ID,
+		"kind":     float64(errors.NotFound),
+	}, body)
+}
+
+func TestWriteProblem_StatusCode(t *testing.T) {
+	rr := httptest.NewRecorder()
+	errors.WriteProblem(rr, nil, fmt.Errorf("plain error"), http.StatusBadGateway)
+
+	assert.Equal(t, http.StatusBadGateway, rr.Code)
+
+	e, err := errors.Parse(rr.Header().Get("Content-Type"), rr.Body.Bytes())
+	require.NoError(t, err)
+	assert.NotEmpty(t, e.ID)
+	assert.Equal(t, errors.Unknown, e.Kind)
+}
+
+func TestRespond(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+	}{
+		{
+			name:        "no accept header",
+			contentType: errors.ContentTypeJSON,
+		},
+		{
+			name:        "any media type",
+			accept:      "*/*",
+			contentType: errors.ContentTypeJSON,
+		},
+		{
+			name:        "json",
+			accept:      "application/json",
+			contentType: errors.ContentTypeJSON,
+		},
+		{
+			name:        "problem details",
+			accept:      "application/problem+json",
+			contentType: errors.ContentTypeProblem,
+		},
+		{
+			name:        "problem details preferred",
+			accept:      "application/json;q=0.5, application/problem+json",
+			contentType: errors.ContentTypeProblem,
+		},
+		{
+			name:        "json preferred",
+			accept:      "application/json, application/problem+json;q=0.9",
+			contentType: errors.ContentTypeJSON,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := errors.New(errors.Forbidden, "access denied").(*errors.Error)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+
+			rr := httptest.NewRecorder()
+			errors.Respond(rr, req, e)
+
+			assert.Equal(t, http.StatusForbidden, rr.Code)
+			assert.Equal(t, test.contentType, rr.Header().Get("Content-Type"))
+
+			// both formats are parsed back into the same error
+			parsed, err := errors.Parse(rr.Header().Get("Content-Type"), rr.Body.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, e.ID, parsed.ID)
+			assert.Equal(t, e.Kind, parsed.Kind)
+			assert.Equal(t, e.Message, parsed.Message)
+		})
+	}
+}
+
+func TestParse_ForeignProblem(t *testing.T) {
+	body := []byte(`{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50."}`)
+
+	e, err := errors.Parse("application/problem+json; charset=utf-8", body)
+	require.NoError(t, err)
+	assert.Equal(t, errors.Forbidden, e.Kind)
+	assert.Equal(t, "Your current balance is 30, but that costs 50.", e.Message)
+
+	_, err = errors.Parse(errors.ContentTypeProblem, []byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestFromResponse_Problem(t *testing.T) {
+	remote := errors.New(errors.NotFound, "policy not found").(*errors.Error)
+
+	rr := httptest.NewRecorder()
+	errors.WriteProblem(rr, nil, remote)
+
+	e := errors.FromResponse(rr.Result(), "failed to evaluate policy")
+	assert.True(t, errors.Is(errors.NotFound, e))
+	ec, ok := errors.As(e)
+	require.True(t, ok)
+	assert.Equal(t, remote.ID, ec.ID)
+	assert.Contains(t, e.Error(), "policy not found")
+}
@@ -2,23 +2,117 @@ package err
 
 import (
 	"crypto/rand"
+	"encoding/binary"
 	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 const Alphabet string = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
 
+// crockford is the Crockford base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// IDGenerator returns a new unique error ID.
+type IDGenerator func() string
+
+var idGenerator atomic.Pointer[IDGenerator]
+
+// SetIDGenerator sets the generator used by NewID and thereby by New.
+// Passing nil restores the default ShortID generator. ULID and UUIDv7
+// generate time-sortable IDs:
+//
+//	errors.SetIDGenerator(errors.ULID)
+func SetIDGenerator(gen IDGenerator) {
+	if gen == nil {
+		idGenerator.Store(nil)
+		return
+	}
+
+	idGenerator.Store(&gen)
+}
+
+// NewID returns a new error ID of the configured generator,
+// see SetIDGenerator.
 func NewID() string {
-	length := 16
+	if gen := idGenerator.Load(); gen != nil {
+		return (*gen)()
+	}
+
+	return ShortID()
+}
+
+// ShortID returns a random ID of 16 characters of the Alphabet.
+func ShortID() string {
+	const length = 16
 
+	// bytes above the largest multiple of the alphabet length are
+	// rejected, so all characters are equally likely
 	ll := len(Alphabet)
-	b := make([]byte, length)
-	_, err := rand.Read(b) // generates len(b) random bytes
-	if err != nil {
-		panic(fmt.Errorf("failed to read random bytes: %v", err))
+	limit := byte(256 / ll * ll)
+
+	id := make([]byte, 0, length)
+	buf := make([]byte, length)
+	for len(id) < length {
+		randomBytes(buf)
+		for _, b := range buf {
+			if b >= limit {
+				continue
+			}
+			id = append(id, Alphabet[int(b)%ll])
+			if len(id) == length {
+				break
+			}
+		}
 	}
-	for i := 0; i < length; i++ {
-		b[i] = Alphabet[int(b[i])%ll]
+
+	return string(id)
+}
+
+// ULID returns a Universally Unique Lexicographically Sortable Identifier
+// of 26 characters: a millisecond timestamp followed by 80 random bits,
+// encoded with the Crockford base32 alphabet.
+func ULID() string {
+	var b [16]byte
+	ms := uint64(time.Now().UnixMilli())
+	binary.BigEndian.PutUint16(b[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(b[2:6], uint32(ms))
+	randomBytes(b[6:])
+
+	bit := func(i int) byte {
+		return b[i/8] >> (7 - i%8) & 1
 	}
 
-	return string(b)
+	// the first character holds the 3 most significant bits,
+	// every other one 5 bits
+	id := make([]byte, 26)
+	id[0] = crockford[b[0]>>5]
+	for i, pos := 1, 3; i < len(id); i++ {
+		var v byte
+		for j := 0; j < 5; j++ {
+			v = v<<1 | bit(pos)
+			pos++
+		}
+		id[i] = crockford[v]
+	}
+
+	return string(id)
+}
+
+// UUIDv7 returns a time-sortable version 7 UUID.
+func UUIDv7() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		panic(fmt.Errorf("failed to generate uuid: %v", err))
+	}
+
+	return id.String()
+}
+
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Errorf("failed to read random bytes: %v", err))
+	}
 }
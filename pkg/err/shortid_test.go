@@ -2,9 +2,12 @@ package err_test
 
 import (
 	"testing"
+	"time"
 
 	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewID(t *testing.T) {
@@ -17,3 +20,52 @@ func TestNewID(t *testing.T) {
 		}
 	}
 }
+
+func TestShortID_Distribution(t *testing.T) {
+	counts := map[rune]int{}
+	for i := 0; i < 2000; i++ {
+		for _, r := range errors.ShortID() {
+			counts[r]++
+		}
+	}
+
+	// 32000 characters, ~516 per character without bias; with the old
+	// modulo bias the first 8 characters were drawn ~25% more often
+	assert.Len(t, counts, len(errors.Alphabet))
+	for r, n := range counts {
+		assert.Less(t, n, 650, "character %q is overrepresented", r)
+	}
+}
+
+func TestULID(t *testing.T) {
+	prev := errors.ULID()
+	for i := 0; i < 3; i++ {
+		time.Sleep(2 * time.Millisecond)
+
+		id := errors.ULID()
+		assert.Len(t, id, 26)
+		assert.Regexp(t, "^[0-7][0-9A-HJKMNP-TV-Z]{25}$", id)
+		assert.Greater(t, id, prev)
+		prev = id
+	}
+}
+
+func TestUUIDv7(t *testing.T) {
+	id, err := uuid.Parse(errors.UUIDv7())
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), id.Version())
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	defer errors.SetIDGenerator(nil)
+
+	errors.SetIDGenerator(errors.ULID)
+	e := errors.New(errors.NotFound).(*errors.Error)
+	assert.Len(t, e.ID, 26)
+
+	errors.SetIDGenerator(func() string { return "fixed" })
+	assert.Equal(t, "fixed", errors.NewID())
+
+	errors.SetIDGenerator(nil)
+	assert.Len(t, errors.NewID(), 16)
+}
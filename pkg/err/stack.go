@@ -0,0 +1,55 @@
+package err
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// maxStackDepth limits the number of frames captured by New.
+const maxStackDepth = 32
+
+var stackCapture atomic.Bool
+
+// SetStackCapture enables or disables capturing the call stack in New.
+// Capturing is disabled by default, because it is expensive. It can be
+// enabled with the errstack build tag or at runtime, e.g. with
+// BaseConfig.SetStackCapture of the config package in development.
+func SetStackCapture(enabled bool) {
+	stackCapture.Store(enabled)
+}
+
+// StackCapture reports whether New captures the call stack.
+func StackCapture() bool {
+	return stackCapture.Load()
+}
+
+// callers returns the program counters of the stack, skipping the
+// given number of frames above the caller of callers.
+func callers(skip int) []uintptr {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	return pcs[:n]
+}
+
+// StackTrace returns the call stack captured when the error was
+// created, or an empty string if stack capture was disabled.
+// Every frame is formatted as function name followed by file and line.
+func (e *Error) StackTrace() string {
+	if e == nil || len(e.stack) == 0 {
+		return ""
+	}
+
+	b := new(strings.Builder)
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+
+	return b.String()
+}
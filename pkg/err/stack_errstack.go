@@ -0,0 +1,7 @@
+//go:build errstack
+
+package err
+
+func init() {
+	SetStackCapture(true)
+}
@@ -0,0 +1,104 @@
+// Package goaerr integrates err.Error with Goa services, so that Goa and
+// gin based services return identical error payloads.
+//
+// Pass Formatter as the formatter and ErrorHandler as the error handler
+// to the generated HTTP server constructors:
+//
+//	srv := server.New(endpoints, mux, dec, enc, goaerr.ErrorHandler(), goaerr.Formatter)
+package goaerr
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+// Formatter is a Goa error formatter which converts errors returned by
+// service methods with ToError. The response is encoded with the JSON
+// representation of err.Error and the status code of its Kind.
+func Formatter(_ context.Context, err error) goahttp.Statuser {
+	return ToError(err)
+}
+
+// ErrorHandler returns a Goa error handler, which is called if encoding
+// a response fails. It logs the error with the logger of the request
+// context and writes it as JSON response.
+func ErrorHandler() func(context.Context, http.ResponseWriter, error) {
+	return func(c context.Context, w http.ResponseWriter, err error) {
+		e := ToError(err)
+		ctx.GetLogger(c).Error(e, "failed to encode response", "errorId", e.ID)
+		errors.JSON(w, e)
+	}
+}
+
+// ToError converts any error to *errors.Error. An *errors.Error in the
+// chain of err is returned as is, a *goa.ServiceError is converted with
+// FromServiceError and all other errors get the Kind Internal.
+func ToError(err error) *errors.Error {
+	if e, ok := errors.As(err); ok {
+		return e
+	}
+
+	var serr *goa.ServiceError
+	if stderrors.As(err, &serr) {
+		return FromServiceError(serr)
+	}
+
+	return errors.New(errors.Internal, err).(*errors.Error)
+}
+
+// FromServiceError converts a Goa service error to *errors.Error.
+//
+// The Kind is derived from the error characteristics the same way Goa
+// derives the status code of its default error response. The Goa error
+// ID and message are kept and validation errors of a field, including
+// merged ones, are returned as field violations.
+func FromServiceError(serr *goa.ServiceError) *errors.Error {
+	e := &errors.Error{
+		ID:      serr.ID,
+		Kind:    kindOf(serr),
+		Message: serr.Message,
+		Err:     serr,
+	}
+
+	for _, h := range serr.History() {
+		if h.Field == nil {
+			continue
+		}
+		e.Violations = append(e.Violations, errors.FieldViolation{
+			Path:    *h.Field,
+			Code:    h.Name,
+			Message: h.Message,
+		})
+	}
+
+	if e.ID == "" {
+		e.ID = errors.NewID()
+	}
+
+	return e
+}
+
+// kindOf mirrors goahttp.ErrorResponse.StatusCode.
+func kindOf(serr *goa.ServiceError) errors.Kind {
+	switch {
+	case serr.Name == goa.UnsupportedMediaType:
+		return errors.UnsupportedMediaType
+	case serr.Fault:
+		return errors.Internal
+	case serr.Timeout && serr.Temporary:
+		return errors.GatewayTimeout
+	case serr.Timeout:
+		return errors.Timeout
+	case serr.Temporary:
+		return errors.ServiceUnavailable
+	default:
+		return errors.BadRequest
+	}
+}
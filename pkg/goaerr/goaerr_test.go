@@ -0,0 +1,92 @@
+package goaerr_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/goaerr"
+)
+
+func TestFormatter(t *testing.T) {
+	e := errors.New(errors.NotFound, "policy not found", errors.InternalMessage("no rows"))
+
+	// goa error encoder with formatter
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/json")
+	ctx := context.WithValue(context.Background(), goahttp.AcceptTypeKey, "application/json")
+
+	goaRR := httptest.NewRecorder()
+	encode := goahttp.ErrorEncoder(goahttp.ResponseEncoder, goaerr.Formatter)
+	require.NoError(t, encode(ctx, goaRR, fmt.Errorf("wrapped: %w", e)))
+
+	// plain err.JSON as used with gin
+	jsonRR := httptest.NewRecorder()
+	errors.JSON(jsonRR, e)
+
+	assert.Equal(t, http.StatusNotFound, goaRR.Code)
+	assert.Equal(t, jsonRR.Code, goaRR.Code)
+	assert.JSONEq(t, jsonRR.Body.String(), goaRR.Body.String())
+	assert.NotContains(t, goaRR.Body.String(), "no rows")
+}
+
+func TestToError(t *testing.T) {
+	e := errors.New(errors.Forbidden, "access denied").(*errors.Error)
+	assert.Same(t, e, goaerr.ToError(e))
+
+	plain := goaerr.ToError(fmt.Errorf("connection refused"))
+	assert.Equal(t, errors.Internal, plain.Kind)
+	assert.Empty(t, plain.Message)
+
+	serr := goaerr.ToError(goa.PermanentError("not_found", "policy %q not found", "example"))
+	assert.Equal(t, errors.BadRequest, serr.Kind)
+	assert.Equal(t, `policy "example" not found`, serr.Message)
+	assert.True(t, errors.Is(errors.BadRequest, serr))
+}
+
+func TestFromServiceError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *goa.ServiceError
+		kind errors.Kind
+	}{
+		{"fault", goa.Fault("boom"), errors.Internal},
+		{"permanent", goa.PermanentError("invalid", "invalid"), errors.BadRequest},
+		{"temporary", goa.TemporaryError("unavailable", "unavailable"), errors.ServiceUnavailable},
+		{"timeout", goa.PermanentTimeoutError("timeout", "timeout"), errors.Timeout},
+		{"temporary timeout", goa.TemporaryTimeoutError("timeout", "timeout"), errors.GatewayTimeout},
+		{"unsupported media type", goa.UnsupportedMediaTypeError("text/plain").(*goa.ServiceError), errors.UnsupportedMediaType},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := goaerr.FromServiceError(test.err)
+			assert.Equal(t, test.kind, e.Kind)
+			assert.Equal(t, test.err.ID, e.ID)
+			assert.Equal(t, test.err.Message, e.Message)
+		})
+	}
+}
+
+func TestFromServiceError_Violations(t *testing.T) {
+	var err error
+	err = goa.MergeErrors(err, goa.MissingFieldError("name", "body"))
+	err = goa.MergeErrors(err, goa.InvalidLengthError("body.id", "x", 1, 3, true))
+
+	e := goaerr.ToError(err)
+	assert.Equal(t, errors.BadRequest, e.Kind)
+	require.Len(t, e.Violations, 2)
+	assert.Equal(t, "name", e.Violations[0].Path)
+	assert.Equal(t, goa.MissingField, e.Violations[0].Code)
+	assert.Equal(t, "body.id", e.Violations[1].Path)
+	assert.Equal(t, goa.InvalidLength, e.Violations[1].Code)
+	assert.Equal(t, `length of body.id must be greater or equal than 3 but got value "x" (len=1)`, e.Violations[1].Message)
+}
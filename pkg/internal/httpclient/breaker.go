@@ -0,0 +1,102 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+// breaker is a circuit breaker for a single upstream.
+//
+// It opens after threshold consecutive failures and rejects calls for
+// the cooldown period. Afterwards a single probe call is let through:
+// its success closes the breaker, its failure opens it again.
+type breaker struct {
+	mu        sync.Mutex
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	threshold int
+	cooldown  time.Duration
+}
+
+func (c *Client) breaker(host string) *breaker {
+	if b, ok := c.breakers.Load(host); ok {
+		return b.(*breaker)
+	}
+
+	b, _ := c.breakers.LoadOrStore(host, &breaker{
+		threshold: c.breakerThreshold,
+		cooldown:  c.breakerCooldown,
+	})
+
+	return b.(*breaker)
+}
+
+// allow reports whether a call may be sent to the upstream.
+func (b *breaker) allow(now time.Time) bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = stateHalfOpen
+		b.probing = true
+		return true
+	case stateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record reports the outcome of an allowed call.
+func (b *breaker) record(success bool, now time.Time) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if success {
+		b.state = stateClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == stateHalfOpen || b.failures >= b.threshold {
+		b.state = stateOpen
+		b.openedAt = now
+	}
+}
+
+// release gives up an allowed call without an outcome.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
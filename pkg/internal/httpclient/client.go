@@ -0,0 +1,273 @@
+// Package httpclient implements the resilient HTTP client shared by the
+// service clients of this module.
+//
+// Every call gets its own timeout. Idempotent calls are retried with an
+// exponential backoff when they fail with a temporary error, honouring
+// the Retry-After header of the upstream. A circuit breaker per upstream
+// host fails calls fast when the upstream keeps failing.
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+const (
+	defaultTimeout          = 10 * time.Second
+	defaultMaxRetries       = 2
+	defaultBackoff          = 100 * time.Millisecond
+	defaultMaxBackoff       = 2 * time.Second
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+
+	// maxRetryAfter is the longest Retry-After period which is waited
+	// for. Responses asking for longer periods are not retried.
+	maxRetryAfter = 30 * time.Second
+)
+
+// Client wraps an *http.Client with timeouts, retries
+// and circuit breaking.
+type Client struct {
+	httpClient *http.Client
+
+	timeout          time.Duration
+	maxRetries       uint64
+	backoff          time.Duration
+	maxBackoff       time.Duration
+	breakerThreshold int
+	breakerCooldown  time.Duration
+
+	breakers sync.Map // upstream host -> *breaker
+	now      func() time.Time
+}
+
+func New(httpClient *http.Client, opts ...Option) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	c := &Client{
+		httpClient:       httpClient,
+		timeout:          defaultTimeout,
+		maxRetries:       defaultMaxRetries,
+		backoff:          defaultBackoff,
+		maxBackoff:       defaultMaxBackoff,
+		breakerThreshold: defaultBreakerThreshold,
+		breakerCooldown:  defaultBreakerCooldown,
+		now:              time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// CallOption configures a single call of Client.Do.
+type CallOption func(*call)
+
+type call struct {
+	idempotent bool
+}
+
+// Idempotent marks the call as safe to retry, regardless of its method.
+func Idempotent() CallOption {
+	return func(c *call) {
+		c.idempotent = true
+	}
+}
+
+// Do sends the request and returns the response. The response body is
+// read completely within the call timeout and buffered in memory, so the
+// caller can read it without further deadlines.
+//
+// An error is returned only if no response was received or the circuit
+// breaker of the upstream is open. Error responses are returned as
+// regular responses after retries are exhausted and can be converted
+// with err.FromResponse.
+func (c *Client) Do(ctx context.Context, req *http.Request, opts ...CallOption) (*http.Response, error) {
+	cl := call{idempotent: isIdempotent(req.Method)}
+	for _, opt := range opts {
+		opt(&cl)
+	}
+
+	b := c.breaker(req.URL.Host)
+
+	var retryAfter time.Duration
+	backoff := retry.NewExponential(c.backoff)
+	backoff = retry.WithCappedDuration(c.maxBackoff, backoff)
+	backoff = retry.WithJitterPercent(10, backoff)
+	backoff = retry.WithMaxRetries(c.maxRetries, backoff)
+	backoff = withRetryAfter(&retryAfter, backoff)
+
+	var resp *http.Response
+	err := retry.Do(ctx, backoff, func(ctx context.Context) error {
+		if !b.allow(c.now()) {
+			return errors.New(errors.ServiceUnavailable, "circuit breaker is open for "+req.URL.Host)
+		}
+
+		var err error
+		resp, err = c.do(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil {
+				// canceled by the caller, says nothing about the upstream
+				b.release()
+				return err
+			}
+
+			b.record(false, c.now())
+			if cl.idempotent {
+				return retry.RetryableError(err)
+			}
+			return err
+		}
+
+		b.record(!upstreamFailure(resp), c.now())
+
+		if cl.idempotent && temporary(resp) {
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), c.now())
+			if retryAfter > maxRetryAfter {
+				return nil
+			}
+			return retry.RetryableError(errRetryResponse)
+		}
+
+		return nil
+	})
+
+	if err == errRetryResponse {
+		// retries exhausted, hand the last response to the caller
+		return resp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+var errRetryResponse = fmt.Errorf("retryable response")
+
+// do sends a single attempt and buffers the response body.
+func (c *Client) do(parent context.Context, req *http.Request) (*http.Response, error) {
+	ctx := parent
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	r := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		return nil, timeoutError(parent, ctx, err)
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, timeoutError(parent, ctx, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
+
+// temporary reports whether the response carries a temporary error by
+// its status code, regardless of the error in the body: 408, 429, 500,
+// 502, 503 and 504.
+func temporary(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// upstreamFailure reports whether the response counts as failure for the
+// circuit breaker. 501 Not Implemented is a permanent answer of a healthy
+// upstream.
+func upstreamFailure(resp *http.Response) bool {
+	return resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented
+}
+
+// timeoutError marks errors caused by the call timeout as GatewayTimeout,
+// as the upstream did not respond in time. Errors caused by the deadline
+// of the caller are returned unchanged.
+func timeoutError(parent, ctx context.Context, err error) error {
+	if parent.Err() == nil && ctx.Err() == context.DeadlineExceeded {
+		return errors.New(errors.GatewayTimeout, "request timed out", err)
+	}
+
+	return err
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
+
+// parseRetryAfter parses the value of a Retry-After header
+// given either in seconds or as HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
+
+// withRetryAfter waits for the duration requested by the upstream
+// instead of the next backoff, if one was given.
+func withRetryAfter(retryAfter *time.Duration, next retry.Backoff) retry.Backoff {
+	return retry.BackoffFunc(func() (time.Duration, bool) {
+		d, stop := next.Next()
+		if stop {
+			return 0, true
+		}
+
+		if *retryAfter > 0 {
+			d = *retryAfter
+			*retryAfter = 0
+		}
+
+		return d, false
+	})
+}
@@ -0,0 +1,255 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+func newTestClient(opts ...Option) *Client {
+	opts = append([]Option{WithRetries(2, time.Millisecond, 5*time.Millisecond)}, opts...)
+	return New(http.DefaultClient, opts...)
+}
+
+func TestClient_RetryIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := newTestClient().Do(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestClient_RetryExhausted(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		errors.JSON(w, errors.New(errors.Internal, "remote failure"))
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := newTestClient().Do(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+
+	err = errors.FromResponse(resp, "unexpected response: "+resp.Status)
+	assert.True(t, errors.Is(errors.Internal, err))
+	assert.Contains(t, err.Error(), "unexpected response: 500 Internal Server Error")
+	assert.Contains(t, err.Error(), "remote failure")
+}
+
+func TestClient_NoRetryNonIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "payload", string(body))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := newTestClient()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("payload"))
+	require.NoError(t, err)
+	resp, err := c.Do(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// the body is sent again on every retry
+	req, err = http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("payload"))
+	require.NoError(t, err)
+	_, err = c.Do(context.Background(), req, Idempotent())
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestClient_NoRetryPermanentError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := newTestClient().Do(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.True(t, errors.Is(errors.NotFound, errors.FromResponse(resp)))
+}
+
+func TestClient_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL, nil)
+	require.NoError(t, err)
+
+	_, err = newTestClient(WithTimeout(20*time.Millisecond)).Do(context.Background(), req)
+	assert.True(t, errors.Is(errors.GatewayTimeout, err))
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	var healthy atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	c := newTestClient(WithCircuitBreaker(2, time.Minute))
+	c.now = func() time.Time { return now }
+
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, nil)
+		require.NoError(t, err)
+		return c.Do(context.Background(), req)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := send()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	}
+
+	// the breaker is open and fails fast
+	_, err := send()
+	assert.True(t, errors.Is(errors.ServiceUnavailable, err))
+	assert.Contains(t, err.Error(), "circuit breaker is open")
+	assert.Equal(t, int32(2), calls.Load())
+
+	// after the cooldown a failing probe opens it again
+	now = now.Add(time.Minute)
+	_, err = send()
+	require.NoError(t, err)
+	_, err = send()
+	assert.True(t, errors.Is(errors.ServiceUnavailable, err))
+	assert.Equal(t, int32(3), calls.Load())
+
+	// a successful probe closes it
+	healthy.Store(true)
+	now = now.Add(time.Minute)
+	resp, err := send()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, err = send()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(5), calls.Load())
+}
+
+func TestClient_CircuitBreakerNotImplemented(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotImplemented)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(WithCircuitBreaker(2, time.Minute))
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, nil)
+		require.NoError(t, err)
+		resp, err := c.Do(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+	}
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestTemporary(t *testing.T) {
+	tests := []struct {
+		status    int
+		temporary bool
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusBadRequest},
+		{status: http.StatusNotFound},
+		{status: http.StatusConflict},
+		{status: http.StatusRequestTimeout, temporary: true},
+		{status: http.StatusTooManyRequests, temporary: true},
+		{status: http.StatusInternalServerError, temporary: true},
+		{status: http.StatusNotImplemented},
+		{status: http.StatusBadGateway, temporary: true},
+		{status: http.StatusServiceUnavailable, temporary: true},
+		{status: http.StatusGatewayTimeout, temporary: true},
+	}
+
+	for _, test := range tests {
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			assert.Equal(t, test.temporary, temporary(&http.Response{StatusCode: test.status}))
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("invalid", now))
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	assert.Equal(t, 10*time.Second, parseRetryAfter(now.Add(10*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Second).Format(http.TimeFormat), now))
+}
+
+func TestClient_RetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	resp, err := newTestClient().Do(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
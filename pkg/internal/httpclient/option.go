@@ -0,0 +1,33 @@
+package httpclient
+
+import "time"
+
+type Option func(*Client)
+
+// WithTimeout sets the timeout of a single call attempt, including
+// reading the response body. A zero value disables the timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetries sets how often idempotent calls are retried
+// and the initial and maximum backoff between attempts.
+func WithRetries(maxRetries uint64, backoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithCircuitBreaker sets the number of consecutive failures which open
+// the circuit breaker of an upstream and how long it stays open.
+// A threshold of zero disables circuit breaking.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.breakerThreshold = threshold
+		c.breakerCooldown = cooldown
+	}
+}
@@ -1,6 +1,7 @@
 package logr
 
 import (
+	"errors"
 	"fmt"
 	"io"
 
@@ -12,6 +13,10 @@ import (
 
 const (
 	DebugLevel = 1
+
+	// ErrorDetailsKey is the key of the structured fields of
+	// errors which implement logr.Marshaler, see Logger.Error.
+	ErrorDetailsKey = "errorDetails"
 )
 
 type Logger struct {
@@ -22,6 +27,18 @@ func (l Logger) Debug(msg string, keyAndValues ...any) {
 	l.V(DebugLevel).Info(msg, keyAndValues...)
 }
 
+// Error logs an error like logr.Logger.Error. If the error or one of the
+// errors it wraps implements logr.Marshaler, like *err.Error does, its
+// fields (e.g. ID, Kind, causes and stack) are logged as ErrorDetailsKey.
+func (l Logger) Error(err error, msg string, keyAndValues ...any) {
+	var marshaler logr.Marshaler
+	if errors.As(err, &marshaler) {
+		keyAndValues = append(keyAndValues, ErrorDetailsKey, marshaler)
+	}
+
+	l.Logger.WithCallDepth(1).Error(err, msg, keyAndValues...)
+}
+
 // New returns a new Logger instance with specified logLevel and devMode.
 //
 // The writer can be used e.g. to save the logs in a file.
@@ -39,6 +56,11 @@ func New(logLevel string, isDev bool, writer io.Writer) (*Logger, error) {
 	return &Logger{Logger: *logger}, nil
 }
 
+// Discard returns a Logger which discards all logs.
+func Discard() Logger {
+	return Logger{Logger: logr.Discard()}
+}
+
 func getLoggerImplementation(writer io.Writer, level *zap.AtomicLevel, isDev bool) (*logr.Logger, error) {
 	config := zap.NewDevelopmentConfig()
 
@@ -9,6 +9,9 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/internal/httpclient"
 )
 
 const (
@@ -21,6 +24,8 @@ const (
 type Client struct {
 	proofManagerAddr string
 	httpClient       *http.Client
+	client           *httpclient.Client
+	clientOpts       []httpclient.Option
 }
 
 // New initializes an OCM service client given the OCM service address
@@ -34,6 +39,8 @@ func New(proofManagerAddr string, opts ...Option) *Client {
 		opt(c)
 	}
 
+	c.client = httpclient.New(c.httpClient, c.clientOpts...)
+
 	return c
 }
 
@@ -49,14 +56,14 @@ func (c *Client) GetLoginProofInvitation(ctx context.Context, credTypes []string
 	v.Add("type", strings.Join(credTypes, ","))
 	req.URL.RawQuery = v.Encode()
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.client.Do(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close() // nolint:errcheck
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected response code: %s", resp.Status)
+		return nil, errors.FromResponse(resp, fmt.Sprintf("unexpected response code: %s", resp.Status))
 	}
 
 	bytes, err := io.ReadAll(resp.Body)
@@ -86,14 +93,14 @@ func (c *Client) SendOutOfBandRequest(ctx context.Context, r map[string]interfac
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.client.Do(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close() // nolint:errcheck
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected response code: %s", resp.Status)
+		return nil, errors.FromResponse(resp, fmt.Sprintf("unexpected response code: %s", resp.Status))
 	}
 
 	bytes, err := io.ReadAll(resp.Body)
@@ -149,14 +156,14 @@ func (c *Client) findByPresentationID(ctx context.Context, presentationID string
 	v.Add("proofRecordId", presentationID)
 	req.URL.RawQuery = v.Encode()
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.client.Do(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close() // nolint:errcheck
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected response code: %s", resp.Status)
+		return nil, errors.FromResponse(resp, fmt.Sprintf("unexpected response code: %s", resp.Status))
 	}
 
 	return io.ReadAll(resp.Body)
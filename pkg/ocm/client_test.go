@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
 	"github.com/eclipse-xfsc/microservice-core-go/pkg/ocm"
 )
 
@@ -197,3 +198,19 @@ func Test_GetRawLoginProofResultErr(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unexpected response code")
 }
+
+func Test_GetLoginProofResultRemoteError(t *testing.T) {
+	remote := errors.New(errors.NotFound, "proof record not found").(*errors.Error)
+	ocmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		errors.JSON(w, remote)
+	}))
+
+	client := ocm.New(ocmServer.URL)
+	res, err := client.GetLoginProofResult(context.Background(), "2cf01406-b15f-4960-a6a7-7bc62cd37a3c")
+
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(errors.NotFound, err))
+	assert.Contains(t, err.Error(), "unexpected response code: 404 Not Found")
+	assert.Contains(t, err.Error(), "proof record not found")
+	assert.Contains(t, err.Error(), remote.ID)
+}
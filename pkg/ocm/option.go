@@ -1,6 +1,11 @@
 package ocm
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/internal/httpclient"
+)
 
 type Option func(*Client)
 
@@ -9,3 +14,30 @@ func WithHTTPClient(client *http.Client) Option {
 		c.httpClient = client
 	}
 }
+
+// WithTimeout sets the timeout of a single request to the OCM.
+// The default is 10 seconds.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.clientOpts = append(c.clientOpts, httpclient.WithTimeout(timeout))
+	}
+}
+
+// WithRetries sets how often failed idempotent requests with a temporary
+// error are retried and the initial and maximum backoff between them.
+// The default is 2 retries with a backoff from 100ms up to 2s.
+func WithRetries(maxRetries uint64, backoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.clientOpts = append(c.clientOpts, httpclient.WithRetries(maxRetries, backoff, maxBackoff))
+	}
+}
+
+// WithCircuitBreaker sets the number of consecutive failures after which
+// requests to the OCM fail fast for the cooldown period.
+// The default is 5 failures and 30 seconds. A threshold of zero
+// disables the circuit breaker.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.clientOpts = append(c.clientOpts, httpclient.WithCircuitBreaker(threshold, cooldown))
+	}
+}
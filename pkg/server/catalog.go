@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swaggo/swag"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+)
+
+const contentTypeMarkdown = "text/markdown"
+
+// EnableErrorCatalog exposes the registered error codes (see
+// errors.RegisterCode) under GET /v1/metrics/errors in the given modes.
+// Without modes, the route is not added. It must be called before any
+// routes are added or the server is started.
+//
+//	server.EnableErrorCatalog(server.ModeDebug, server.ModeTesting, server.ModeProduction)
+func (s *GinServer) EnableErrorCatalog(modes ...ServerMode) {
+	s.errorCatalogModes = modes
+}
+
+func (s *GinServer) errorCatalogEnabled() bool {
+	return slices.Contains(s.errorCatalogModes, ServerMode(s.mode))
+}
+
+// getErrorCatalogHandler godoc
+//
+// @Summary		Error code catalog
+// @Description	lists the registered error codes with their kind, status code and default message
+// @Tags		docs
+// @Produce		json
+// @Produce		text/markdown
+// @Success		200
+// @Router		/errors [get]
+// x-servers basePath=/v1/metrics
+func getErrorCatalogHandler() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if strings.Contains(c.GetHeader("Accept"), contentTypeMarkdown) {
+			c.Header("Content-Type", contentTypeMarkdown+"; charset=utf-8")
+			c.Status(http.StatusOK)
+			_ = errors.WriteCatalogMarkdown(c.Writer)
+			return
+		}
+
+		c.Header("Content-Type", "application/json; charset=utf-8")
+		c.Status(http.StatusOK)
+		_ = errors.WriteCatalogJSON(c.Writer)
+	}
+}
+
+// SwaggerWithErrorCatalog wraps a swagger document, so the registered
+// error codes are added to it as x-error-codes extension when it is read.
+// The wrapped document must be registered under its own instance name,
+// which is then passed to ginSwagger.InstanceName by the Environment:
+//
+//	swag.Register("errors", server.SwaggerWithErrorCatalog(docs.SwaggerInfo))
+func SwaggerWithErrorCatalog(doc swag.Swagger) swag.Swagger {
+	return catalogDoc{doc: doc}
+}
+
+type catalogDoc struct {
+	doc swag.Swagger
+}
+
+// ReadDoc returns the wrapped document with the error catalog, or the
+// wrapped document as is if it can't be decoded.
+func (d catalogDoc) ReadDoc() string {
+	doc := d.doc.ReadDoc()
+
+	merged, err := errors.MergeCatalog([]byte(doc))
+	if err != nil {
+		return doc
+	}
+
+	return string(merged)
+}
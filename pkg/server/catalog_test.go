@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/server/environment"
+)
+
+var codeTenantUnknown = errors.RegisterCode(errors.CodeInfo{
+	Code:    "TENANT_UNKNOWN",
+	Kind:    errors.NotFound,
+	Message: "tenant unknown",
+})
+
+type staticDoc string
+
+func (d staticDoc) ReadDoc() string {
+	return string(d)
+}
+
+func TestGinServer_ErrorCatalog(t *testing.T) {
+	// the route is opt-in
+	srv := New(environment.NewDefaultEnv(), ModeTesting)
+	srv.initOnce()
+
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/metrics/errors", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	srv = New(environment.NewDefaultEnv(), ModeProduction)
+	srv.EnableErrorCatalog(ModeTesting)
+	srv.initOnce()
+
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/metrics/errors", nil))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	srv = New(environment.NewDefaultEnv(), ModeTesting)
+	srv.EnableErrorCatalog(ModeTesting)
+	srv.initOnce()
+
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/metrics/errors", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var catalog []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &catalog))
+	assert.Contains(t, catalog, map[string]interface{}{
+		"code":    string(codeTenantUnknown),
+		"kind":    "not found",
+		"status":  float64(http.StatusNotFound),
+		"message": "tenant unknown",
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/metrics/errors", nil)
+	req.Header.Set("Accept", "text/markdown")
+	rr = httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, req)
+	assert.Contains(t, rr.Header().Get("Content-Type"), "text/markdown")
+	assert.Contains(t, rr.Body.String(), "| TENANT_UNKNOWN | not found | 404 | tenant unknown |")
+}
+
+func TestSwaggerWithErrorCatalog(t *testing.T) {
+	doc := SwaggerWithErrorCatalog(staticDoc(`{"swagger":"2.0"}`)).ReadDoc()
+	assert.Contains(t, doc, `"x-error-codes"`)
+	assert.Contains(t, doc, string(codeTenantUnknown))
+
+	// invalid documents are returned as is
+	assert.Equal(t, "invalid", SwaggerWithErrorCatalog(staticDoc("invalid")).ReadDoc())
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfigProvider returns the effective configuration of the service,
+// e.g. config.Loader.Effective. Secrets must be redacted by the provider.
+type ConfigProvider func() any
+
+// SetConfigProvider exposes the configuration returned by the provider
+// under GET /v1/metrics/config. The route is only added in the given
+// modes, so exposing the configuration is an explicit decision per mode;
+// without modes, it is never added. The route has no authentication, so
+// enable it only where /v1/metrics is not publicly reachable. It must be
+// called before any routes are added or the server is started.
+//
+//	server.SetConfigProvider(func() any { return loader.Effective() }, server.ModeDebug, server.ModeTesting)
+func (s *GinServer) SetConfigProvider(provider ConfigProvider, modes ...ServerMode) {
+	s.configProvider = provider
+	s.configModes = modes
+}
+
+func (s *GinServer) configEnabled() bool {
+	if s.configProvider == nil {
+		return false
+	}
+
+	return slices.Contains(s.configModes, ServerMode(s.mode))
+}
+
+// getConfigHandler godoc
+//
+// @Summary		Effective configuration
+// @Description	lists the configuration values with their source, secrets are redacted
+// @Tags		docs
+// @Produce		json
+// @Success		200
+// @Router		/config [get]
+// x-servers basePath=/v1/metrics
+func getConfigHandler(provider ConfigProvider) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, provider())
+	}
+}
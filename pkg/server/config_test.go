@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/server/environment"
+)
+
+func TestGinServer_Config(t *testing.T) {
+	provider := func() any {
+		return map[string]string{"logLevel": "debug"}
+	}
+
+	tests := []struct {
+		name     string
+		mode     ServerMode
+		provider ConfigProvider
+		modes    []ServerMode
+		status   int
+	}{
+		{name: "testing enabled", mode: ModeTesting, provider: provider, modes: []ServerMode{ModeDebug, ModeTesting}, status: http.StatusOK},
+		{name: "testing not enabled", mode: ModeTesting, provider: provider, modes: []ServerMode{ModeDebug}, status: http.StatusNotFound},
+		{name: "no modes", mode: ModeDebug, provider: provider, status: http.StatusNotFound},
+		{name: "production", mode: ModeProduction, provider: provider, modes: []ServerMode{ModeDebug, ModeTesting}, status: http.StatusNotFound},
+		{name: "production enabled", mode: ModeProduction, provider: provider, modes: []ServerMode{ModeProduction}, status: http.StatusOK},
+		{name: "no provider", mode: ModeTesting, modes: []ServerMode{ModeTesting}, status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := New(environment.NewDefaultEnv(), tt.mode)
+			srv.SetConfigProvider(tt.provider, tt.modes...)
+			srv.initOnce()
+
+			rr := httptest.NewRecorder()
+			srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/metrics/config", nil))
+			assert.Equal(t, tt.status, rr.Code)
+			if tt.status == http.StatusOK {
+				assert.JSONEq(t, `{"logLevel": "debug"}`, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+)
+
+// Headers of the request and trace IDs, see RequestContext.
+const (
+	HeaderRequestID   = "X-Request-ID"
+	HeaderTraceParent = "traceparent"
+)
+
+// RequestContext returns a gin middleware which stores the request and
+// trace IDs in the request context (see ctx.GetRequestID and
+// ctx.GetTraceID), so errors built with errors.NewWithContext, e.g. by
+// ErrorHandler, are linked to them.
+//
+// The request ID is taken from the X-Request-ID header or generated, and
+// returned in the X-Request-ID header of the response. As it ends up in
+// error IDs and logs, the header is only accepted with up to 64 letters,
+// digits, dots, underscores and hyphens. The trace ID is taken from the
+// W3C traceparent header, if any.
+func RequestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(HeaderRequestID, requestID)
+
+		rc := ctx.WithRequestID(c.Request.Context(), requestID)
+		if traceID := traceIDOf(c.GetHeader(HeaderTraceParent)); traceID != "" {
+			rc = ctx.WithTraceID(rc, traceID)
+		}
+		c.Request = c.Request.WithContext(rc)
+
+		c.Next()
+	}
+}
+
+// maxRequestIDLength is the maximum length of accepted request IDs.
+const maxRequestIDLength = 64
+
+// validRequestID reports whether a client supplied request ID is
+// non-empty, not too long and consists of [A-Za-z0-9._-] only.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.' || r == '_' || r == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// traceIDOf returns the trace ID of a traceparent header of the form
+// <version>-<trace ID>-<parent ID>-<flags>, or an empty string if the
+// header is invalid.
+func traceIDOf(traceparent string) string {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || strings.Trim(parts[1], "0") == "" {
+		return ""
+	}
+
+	for _, r := range parts[1] {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return ""
+		}
+	}
+
+	return parts[1]
+}
+
+// ErrorHandler returns a gin middleware which turns errors added to the
+// context with c.Error(...) and recovered panics into err.Error responses.
+//
+// Errors are logged with the request-scoped logger (see ctx.GetLogger),
+// or the given logger if the request context has none. The response is
+// written in the format preferred by the client (see errors.Respond) with
+// the status code of the error Kind, unless the handler already wrote one.
+// If several errors were added, the last one is returned to the client.
+//
+// Gin binding errors which are not *errors.Error get the Kind BadRequest,
+// all others and recovered panics get the Kind Internal, unless they
+// carry a Kind already.
+//
+// The internal message and causes of an error are never serialized for
+// clients. Only in ModeDebug they are appended to the message of the
+// response to ease debugging.
+func ErrorHandler(mode ServerMode, logger logr.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+				recoverPanic(c, r, requestLogger(c, logger))
+			}
+
+			handleErrors(c, mode, requestLogger(c, logger))
+		}()
+
+		c.Next()
+	}
+}
+
+// recoverPanic adds the recovered value as error to the context and
+// aborts the request.
+func recoverPanic(c *gin.Context, r any, logger logr.Logger) {
+	err, ok := r.(error)
+	if !ok {
+		err = fmt.Errorf("%v", r)
+	}
+
+	// the connection is gone, there is no one to respond to
+	if stderrors.Is(err, syscall.EPIPE) || stderrors.Is(err, syscall.ECONNRESET) {
+		logger.Error(err, "connection closed by client", "method", c.Request.Method, "path", c.Request.URL.Path)
+		c.Abort()
+		return
+	}
+
+	kind := errors.KindOf(err)
+	if kind == errors.Unknown {
+		kind = errors.Internal
+	}
+
+	e := errors.NewWithContext(c.Request.Context(), kind, fmt.Errorf("panic recovered: %w", err))
+	_ = c.Error(e).SetMeta(recovered{stack: debug.Stack()})
+	c.Abort()
+}
+
+// recovered is the meta data of errors added by recoverPanic.
+type recovered struct {
+	stack []byte
+}
+
+// handleErrors logs the errors of the context and writes the last one as
+// response.
+func handleErrors(c *gin.Context, mode ServerMode, logger logr.Logger) {
+	if len(c.Errors) == 0 {
+		return
+	}
+
+	var last *errors.Error
+	for _, ginErr := range c.Errors {
+		last = toError(c, ginErr)
+
+		keysAndValues := []any{
+			"errorId", last.ID,
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+		}
+		if meta, ok := ginErr.Meta.(recovered); ok {
+			keysAndValues = append(keysAndValues, "stack", string(meta.stack))
+		}
+
+		logger.Error(last, "request failed", keysAndValues...)
+	}
+
+	if c.Writer.Written() {
+		return
+	}
+
+	resp := *last
+	if mode == ModeDebug {
+		resp.Message = debugMessage(last)
+	}
+
+	errors.Respond(c.Writer, c.Request, &resp)
+	c.Abort()
+}
+
+// toError converts a gin error to *errors.Error. Errors created here
+// record the request, tenant and trace IDs of the request context.
+func toError(c *gin.Context, ginErr *gin.Error) *errors.Error {
+	if e, ok := errors.As(ginErr.Err); ok {
+		return e
+	}
+
+	if ginErr.IsType(gin.ErrorTypeBind) {
+		return errors.NewWithContext(c.Request.Context(), errors.BadRequest, "invalid request", ginErr.Err).(*errors.Error)
+	}
+
+	return errors.NewWithContext(c.Request.Context(), errors.Internal, ginErr.Err).(*errors.Error)
+}
+
+// debugMessage returns the message of the error extended by its
+// internal message and causes.
+func debugMessage(e *errors.Error) string {
+	parts := make([]string, 0, 2)
+	if e.Message != "" {
+		parts = append(parts, e.Message)
+	}
+	if e.InternalMessage != "" {
+		parts = append(parts, e.InternalMessage)
+	}
+	parts = append(parts, e.Causes()...)
+
+	return strings.Join(parts, ": ")
+}
+
+// requestLogger returns the logger of the request context or the given
+// fallback logger.
+func requestLogger(c *gin.Context, fallback logr.Logger) logr.Logger {
+	if logger := ctx.GetLogger(c.Request.Context()); logger.GetSink() != nil {
+		return logger
+	}
+
+	return fallback
+}
+
+// tenantContext stores the tenant ID of the route in the request context,
+// see ctx.GetTenantID.
+func tenantContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if id := c.Param(strings.TrimPrefix(RouteParamTenantID, ":")); id != "" {
+			c.Request = c.Request.WithContext(ctx.WithTenantID(c.Request.Context(), id))
+		}
+		c.Next()
+	}
+}
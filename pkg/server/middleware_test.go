@@ -0,0 +1,234 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/ctx"
+	errors "github.com/eclipse-xfsc/microservice-core-go/pkg/err"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/server/environment"
+)
+
+func newTestRouter(t *testing.T, mode ServerMode, logs *bytes.Buffer) *gin.Engine {
+	logger, err := logr.New("info", false, logs)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(ErrorHandler(mode, *logger))
+
+	router.GET("/error", func(c *gin.Context) {
+		_ = c.Error(errors.New(errors.NotFound, "policy not found", errors.InternalMessage("no rows in table policies")))
+	})
+	router.GET("/plain", func(c *gin.Context) {
+		_ = c.Error(fmt.Errorf("connection refused"))
+	})
+	router.GET("/bind", func(c *gin.Context) {
+		var body struct {
+			Name string `json:"name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			_ = c.Error(err).SetType(gin.ErrorTypeBind)
+		}
+	})
+	router.GET("/written", func(c *gin.Context) {
+		c.String(http.StatusAccepted, "accepted")
+		_ = c.Error(errors.New(errors.Internal, "background failure"))
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("nil map")
+	})
+
+	return router
+}
+
+func serve(router *gin.Engine, path string) (*httptest.ResponseRecorder, *errors.Error) {
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, bytes.NewReader([]byte("{}"))))
+
+	e, _ := errors.Parse(rr.Header().Get("Content-Type"), rr.Body.Bytes())
+	return rr, e
+}
+
+func TestErrorHandler(t *testing.T) {
+	logs := new(bytes.Buffer)
+	router := newTestRouter(t, ModeProduction, logs)
+
+	rr, e := serve(router, "/error")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	require.NotNil(t, e)
+	assert.Equal(t, errors.NotFound, e.Kind)
+	assert.Equal(t, "policy not found", e.Message)
+	assert.NotContains(t, rr.Body.String(), "no rows")
+	assert.Contains(t, logs.String(), e.ID)
+	assert.Contains(t, logs.String(), "no rows in table policies")
+
+	rr, e = serve(router, "/plain")
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.NotNil(t, e)
+	assert.Empty(t, e.Message)
+	assert.NotContains(t, rr.Body.String(), "connection refused")
+
+	rr, e = serve(router, "/bind")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	require.NotNil(t, e)
+	assert.Equal(t, "invalid request", e.Message)
+
+	rr, _ = serve(router, "/written")
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Equal(t, "accepted", rr.Body.String())
+	assert.Contains(t, logs.String(), "background failure")
+}
+
+func TestErrorHandler_Panic(t *testing.T) {
+	logs := new(bytes.Buffer)
+	router := newTestRouter(t, ModeProduction, logs)
+
+	rr, e := serve(router, "/panic")
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.NotNil(t, e)
+	assert.Equal(t, errors.Internal, e.Kind)
+	assert.NotContains(t, rr.Body.String(), "nil map")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, e.ID, entry["errorId"])
+	assert.Contains(t, entry["error"], "panic recovered: nil map")
+	assert.Contains(t, entry["stack"], "runtime/debug.Stack")
+}
+
+func TestErrorHandler_DebugMode(t *testing.T) {
+	router := newTestRouter(t, ModeDebug, new(bytes.Buffer))
+
+	_, e := serve(router, "/error")
+	require.NotNil(t, e)
+	assert.Equal(t, "policy not found: no rows in table policies", e.Message)
+
+	_, e = serve(router, "/plain")
+	require.NotNil(t, e)
+	assert.Equal(t, "connection refused", e.Message)
+}
+
+func TestErrorHandler_TestingMode(t *testing.T) {
+	router := newTestRouter(t, ModeTesting, new(bytes.Buffer))
+
+	rr, e := serve(router, "/error")
+	require.NotNil(t, e)
+	assert.Equal(t, "policy not found", e.Message)
+	assert.NotContains(t, rr.Body.String(), "no rows")
+}
+
+func TestErrorHandler_RequestLogger(t *testing.T) {
+	router := newTestRouter(t, ModeProduction, new(bytes.Buffer))
+
+	logs := new(bytes.Buffer)
+	logger, err := logr.New("info", false, logs)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/error", nil)
+	req = req.WithContext(ctx.WithLogger(context.Background(), *logger))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Contains(t, logs.String(), "policy not found")
+}
+
+func TestErrorHandler_Localized(t *testing.T) {
+	logs := new(bytes.Buffer)
+	router := newTestRouter(t, ModeProduction, logs)
+
+	req := httptest.NewRequest(http.MethodGet, "/plain", nil)
+	req.Header.Set("Accept-Language", "fr-FR, fr;q=0.9")
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, "fr", rr.Header().Get("Content-Language"))
+
+	e, err := errors.Parse(rr.Header().Get("Content-Type"), rr.Body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "Une erreur interne s'est produite.", e.Message)
+	assert.NotContains(t, logs.String(), "Une erreur interne")
+}
+
+func TestGinServer_TenantContext(t *testing.T) {
+	srv := New(environment.NewDefaultEnv(), ModeTesting)
+	srv.AddHandler(http.MethodGet, "/tenant", func(c *gin.Context) {
+		c.String(http.StatusOK, ctx.GetTenantID(c.Request.Context()))
+	})
+
+	rr := httptest.NewRecorder()
+	srv.router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/tenants/acme/tenant", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "acme", rr.Body.String())
+}
+
+func TestRequestContext(t *testing.T) {
+	logs := new(bytes.Buffer)
+	logger, err := logr.New("info", false, logs)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(RequestContext(), ErrorHandler(ModeProduction, *logger))
+	router.GET("/plain", func(c *gin.Context) {
+		_ = c.Error(fmt.Errorf("connection refused"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/plain", nil)
+	req.Header.Set(HeaderRequestID, "req-123")
+	req.Header.Set(HeaderTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, "req-123", rr.Header().Get(HeaderRequestID))
+
+	e, err := errors.Parse(rr.Header().Get("Content-Type"), rr.Body.Bytes())
+	require.NoError(t, err)
+	assert.Regexp(t, `^req-123-`, e.ID)
+	assert.Equal(t, "req-123", e.Metadata[errors.MetadataRequestID])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", e.Metadata[errors.MetadataTraceID])
+	assert.Contains(t, logs.String(), e.ID)
+
+	// a request ID is generated, an invalid traceparent is ignored
+	req = httptest.NewRequest(http.MethodGet, "/plain", nil)
+	req.Header.Set(HeaderTraceParent, "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	requestID := rr.Header().Get(HeaderRequestID)
+	assert.NotEmpty(t, requestID)
+	e, err = errors.Parse(rr.Header().Get("Content-Type"), rr.Body.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, requestID, e.Metadata[errors.MetadataRequestID])
+	assert.NotContains(t, e.Metadata, errors.MetadataTraceID)
+
+	// invalid request IDs are replaced
+	for _, invalid := range []string{"req 123", "req-123\nforged: log", strings.Repeat("a", 65)} {
+		req = httptest.NewRequest(http.MethodGet, "/plain", nil)
+		req.Header.Set(HeaderRequestID, invalid)
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		requestID = rr.Header().Get(HeaderRequestID)
+		assert.NotEqual(t, invalid, requestID)
+		assert.Regexp(t, `^[0-9a-f-]{36}$`, requestID)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/plain", nil)
+	req.Header.Set(HeaderRequestID, strings.Repeat("a", 60)+"._-Z")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, strings.Repeat("a", 60)+"._-Z", rr.Header().Get(HeaderRequestID))
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	ginSwagger "github.com/swaggo/gin-swagger"
+
+	"github.com/eclipse-xfsc/microservice-core-go/pkg/logr"
 )
 
 type Environment interface {
@@ -32,6 +34,12 @@ type GinServer struct {
 	router          *gin.Engine
 	routerGroups    sync.Map
 	healthHandlerFn func(ctx *gin.Context)
+	logger          logr.Logger
+
+	configProvider ConfigProvider
+	configModes    []ServerMode
+
+	errorCatalogModes []ServerMode
 
 	// initOnce uses sync.OnceFunc to call
 	// GinServer.resetRoutes
@@ -178,13 +186,29 @@ func (s *GinServer) SetHealthHandler(fn func(ctx *gin.Context)) {
 	s.healthHandlerFn = fn
 }
 
+// SetLogger sets the logger used by the ErrorHandler middleware for
+// requests whose context carries no logger. It must be called before
+// any routes are added or the server is started.
+func (s *GinServer) SetLogger(logger logr.Logger) {
+	s.logger = logger
+}
+
 func (s *GinServer) resetRoutes() {
-	s.router = gin.Default()
+	if s.logger.GetSink() == nil {
+		if logger, err := logr.New("info", s.mode == string(ModeDebug), gin.DefaultErrorWriter); err == nil {
+			s.logger = *logger
+		} else {
+			s.logger = logr.Discard()
+		}
+	}
+
+	s.router = gin.New()
+	s.router.Use(gin.Logger(), RequestContext(), ErrorHandler(ServerMode(s.mode), s.logger))
 
 	v1 := s.router.Group("/v1")
 	s.routerGroups.Store(routerGroupV1, v1)
 
-	tenants := v1.Group(fmt.Sprintf("/tenants/%s", RouteParamTenantID))
+	tenants := v1.Group(fmt.Sprintf("/tenants/%s", RouteParamTenantID), tenantContext())
 	s.routerGroups.Store(routerGroupTenants, tenants)
 
 	metrics := v1.Group("/metrics")
@@ -192,6 +216,12 @@ func (s *GinServer) resetRoutes() {
 	s.environment.SetSwaggerBasePath(strings.Replace(tenants.BasePath(), RouteParamTenantID, RouteParamTenantIDSwaggerNotation, 1))
 
 	metrics.GET("/health", s.healthHandlerFn)
+	if s.errorCatalogEnabled() {
+		metrics.GET("/errors", getErrorCatalogHandler())
+	}
+	if s.configEnabled() {
+		metrics.GET("/config", getConfigHandler(s.configProvider))
+	}
 
 	s.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler, s.environment.SwaggerOptions()...))
 